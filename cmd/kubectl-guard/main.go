@@ -4,6 +4,8 @@ package main
 import (
 	"fmt"
 	"os"
+	// schedule timezones must resolve where the system has no zoneinfo, e.g. on Windows
+	_ "time/tzdata"
 
 	"github.com/sivchari/kubectl-guard/internal/cli"
 )
@@ -0,0 +1,181 @@
+// Package audit provides the audit log of kubectl-guard decisions.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sivchari/kubectl-guard/internal/config"
+)
+
+// Decision is what happened to a guarded command.
+type Decision string
+
+const (
+	// DecisionWarned is a command that ran after a warning.
+	DecisionWarned Decision = "warned"
+	// DecisionConfirmed is a command that ran after it was confirmed.
+	DecisionConfirmed Decision = "confirmed"
+	// DecisionUnconfirmed is a command needing confirmation that ran without a terminal to ask, failing open.
+	DecisionUnconfirmed Decision = "unconfirmed"
+	// DecisionAborted is a command whose confirmation was refused.
+	DecisionAborted Decision = "aborted"
+	// DecisionOverridden is a command that ran with the guard overridden.
+	DecisionOverridden Decision = "overridden"
+	// DecisionBlocked is a command that was blocked.
+	DecisionBlocked Decision = "blocked"
+	// DecisionDenied is a command that was denied.
+	DecisionDenied Decision = "denied"
+)
+
+// Record is a single audit log entry.
+// Records are chained: each one carries the hash of the previous one and its own hash.
+type Record struct {
+	Seq            uint64    `json:"seq"`
+	Time           time.Time `json:"time"`
+	OSUser         string    `json:"osUser"`
+	KubeUser       string    `json:"kubeUser,omitempty"` // user in the kubeconfig
+	Context        string    `json:"context"`
+	Namespace      string    `json:"namespace"`
+	Args           []string  `json:"args"`
+	Decision       Decision  `json:"decision"`
+	Reason         string    `json:"reason,omitempty"`
+	ExitCode       *int      `json:"exitCode,omitempty"`       // kubectl's exit code, nil when it did not run
+	OverrideReason string    `json:"overrideReason,omitempty"` // why the guard was overridden
+	PrevHash       string    `json:"prevHash"`
+	Hash           string    `json:"hash"`
+}
+
+// computeHash returns the hex SHA-256 of the record's JSON encoding without its hash.
+func (r *Record) computeHash() (string, error) {
+	unhashed := *r
+	unhashed.Hash = ""
+	data, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DefaultPath returns the default audit log path, next to the config file.
+func DefaultPath() (string, error) {
+	path, err := config.DefaultPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "guard-audit.log"), nil
+}
+
+// Path returns the audit log path of the config, or the default path.
+func Path(cfg *config.Config) (string, error) {
+	if cfg.AuditLog != "" {
+		return cfg.AuditLog, nil
+	}
+	return DefaultPath()
+}
+
+// Open returns the audit log of the config.
+func Open(cfg *config.Config) (*Log, error) {
+	path, err := Path(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{
+		Path:    path,
+		MaxSize: cfg.AuditRotation.MaxSize,
+		MaxAge:  cfg.AuditRotation.MaxAge,
+	}, nil
+}
+
+// CurrentUser returns the name of the OS user running kubectl-guard.
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// Filter selects records. Zero fields match every record.
+type Filter struct {
+	Since    time.Time
+	Until    time.Time
+	Context  string // context name or pattern
+	Decision Decision
+}
+
+// Matches checks if the record is selected by the filter.
+func (f *Filter) Matches(r *Record) bool {
+	switch {
+	case !f.Since.IsZero() && r.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !r.Time.Before(f.Until):
+		return false
+	case f.Context != "" && !config.MatchPattern(f.Context, r.Context):
+		return false
+	case f.Decision != "" && r.Decision != f.Decision:
+		return false
+	}
+	return true
+}
+
+// decisions lists every decision.
+var decisions = []Decision{
+	DecisionWarned, DecisionConfirmed, DecisionUnconfirmed, DecisionAborted,
+	DecisionOverridden, DecisionBlocked, DecisionDenied,
+}
+
+// ParseDecision parses a decision name.
+func ParseDecision(s string) (Decision, error) {
+	for _, d := range decisions {
+		if string(d) == s {
+			return d, nil
+		}
+	}
+	return "", fmt.Errorf("invalid decision %q", s)
+}
+
+// ParseTime parses an absolute time (RFC 3339 or a date such as 2006-01-02, in local time)
+// or a duration before now, such as "90m", "24h" or "7d".
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// String formats the record as a single line, e.g.
+// "2026-01-02T15:04:05Z overridden prod/payments alice: kubectl delete pod nginx (exit 0): blocked command".
+func (r *Record) String() string {
+	s := fmt.Sprintf("%s %s %s/%s %s: kubectl %s",
+		r.Time.Format(time.RFC3339), r.Decision, r.Context, r.Namespace, r.OSUser, strings.Join(r.Args, " "))
+	if r.ExitCode != nil {
+		s += fmt.Sprintf(" (exit %d)", *r.ExitCode)
+	}
+	if r.Reason != "" {
+		s += ": " + r.Reason
+	}
+	if r.OverrideReason != "" {
+		s += " (reason: " + r.OverrideReason + ")"
+	}
+	return s
+}
@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestAppendAndRead(t *testing.T) {
+	log := &Log{Path: filepath.Join(t.TempDir(), "logs", "audit.log")}
+
+	records, err := log.Read()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("expected no records for a missing log, got %v (%v)", records, err)
+	}
+
+	first := &Record{
+		Time:      time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+		OSUser:    "alice",
+		KubeUser:  "prod-admin",
+		Context:   "prod",
+		Namespace: "payments",
+		Args:      []string{"delete", "pod", "nginx", "--guard-override", "--guard-reason=INC-42"},
+		Decision:  DecisionOverridden,
+		Reason:    "blocked command",
+		ExitCode:  intPtr(0),
+
+		OverrideReason: "INC-42",
+	}
+	second := &Record{
+		Time:      time.Date(2026, 1, 3, 9, 0, 0, 0, time.UTC),
+		OSUser:    "bob",
+		Context:   "prod",
+		Namespace: "default",
+		Args:      []string{"delete", "ns", "payments"},
+		Decision:  DecisionDenied,
+	}
+	for _, r := range []*Record{first, second} {
+		if err := log.Append(r); err != nil {
+			t.Fatalf("failed to append: %v", err)
+		}
+	}
+
+	info, err := os.Stat(log.Path)
+	if err != nil {
+		t.Fatalf("failed to stat log: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected mode 0600, got %o", perm)
+	}
+
+	records, err = log.Read()
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if got := records[0].String(); got != first.String() {
+		t.Errorf("expected %q, got %q", first.String(), got)
+	}
+	if records[0].Seq != 1 || records[1].Seq != 2 || records[1].PrevHash != records[0].Hash {
+		t.Errorf("expected chained records, got %+v", records)
+	}
+	if records[1].ExitCode != nil {
+		t.Errorf("expected no exit code, got %d", *records[1].ExitCode)
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	if err := os.WriteFile(path, []byte("{not json}\n"), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := (&Log{Path: path}).Read(); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestRecord_String(t *testing.T) {
+	r := Record{
+		Time:      time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+		OSUser:    "alice",
+		Context:   "prod",
+		Namespace: "payments",
+		Args:      []string{"delete", "pod", "nginx"},
+		Decision:  DecisionOverridden,
+		Reason:    "blocked command",
+		ExitCode:  intPtr(1),
+
+		OverrideReason: "INC-42",
+	}
+	expected := "2026-01-02T15:04:05Z overridden prod/payments alice: kubectl delete pod nginx (exit 1): blocked command (reason: INC-42)"
+	if result := r.String(); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	r := &Record{
+		Time:     time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC),
+		Context:  "prod-eu",
+		Decision: DecisionBlocked,
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected bool
+	}{
+		{name: "empty filter", filter: Filter{}, expected: true},
+		{name: "since before", filter: Filter{Since: r.Time.Add(-time.Hour)}, expected: true},
+		{name: "since is inclusive", filter: Filter{Since: r.Time}, expected: true},
+		{name: "since after", filter: Filter{Since: r.Time.Add(time.Hour)}, expected: false},
+		{name: "until after", filter: Filter{Until: r.Time.Add(time.Hour)}, expected: true},
+		{name: "until is exclusive", filter: Filter{Until: r.Time}, expected: false},
+		{name: "context", filter: Filter{Context: "prod-eu"}, expected: true},
+		{name: "context pattern", filter: Filter{Context: "prod-*"}, expected: true},
+		{name: "other context", filter: Filter{Context: "staging"}, expected: false},
+		{name: "decision", filter: Filter{Decision: DecisionBlocked}, expected: true},
+		{name: "other decision", filter: Filter{Decision: DecisionOverridden}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.filter.Matches(r); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{input: "2026-01-02T15:04:05Z", expected: time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{input: "2026-01-02", expected: time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local)},
+		{input: "90m", expected: now.Add(-90 * time.Minute)},
+		{input: "24h", expected: now.Add(-24 * time.Hour)},
+		{input: "7d", expected: now.AddDate(0, 0, -7)},
+	}
+
+	for _, tt := range tests {
+		result, err := ParseTime(tt.input, now)
+		if err != nil {
+			t.Errorf("ParseTime(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if !result.Equal(tt.expected) {
+			t.Errorf("ParseTime(%q) = %v, expected %v", tt.input, result, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"", "yesterday", "-1h", "3w"} {
+		if _, err := ParseTime(input, now); err == nil {
+			t.Errorf("ParseTime(%q): expected error", input)
+		}
+	}
+}
+
+func TestParseDecision(t *testing.T) {
+	if d, err := ParseDecision("overridden"); err != nil || d != DecisionOverridden {
+		t.Errorf("unexpected decision %q (%v)", d, err)
+	}
+	if _, err := ParseDecision("allowed"); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,332 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rotatedSuffix matches the suffix of rotated files, which sorts by rotation time.
+var rotatedSuffix = regexp.MustCompile(`^\.\d{8}T\d{6}\.\d{9}Z$`)
+
+const (
+	rotatedFormat = "20060102T150405.000000000Z"
+	lockTimeout   = 5 * time.Second
+	staleLock     = 30 * time.Second
+)
+
+// Log is a hash-chained audit log, rotated into timestamped files next to Path.
+// A ".head" file next to Path records the last sequence number and hash,
+// so that truncating the log is detected as well.
+type Log struct {
+	Path    string
+	MaxSize int64         // rotate once the current file would exceed this size; zero means no limit
+	MaxAge  time.Duration // rotate once the first record of the current file is older; zero means no limit
+}
+
+// head is the end of the chain.
+type head struct {
+	seq  uint64
+	hash string
+}
+
+func (l *Log) headPath() string {
+	return l.Path + ".head"
+}
+
+// Files returns the rotated files, oldest first, followed by the current file if it exists.
+func (l *Log) Files() ([]string, error) {
+	matches, err := filepath.Glob(globEscape(l.Path) + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, m := range matches {
+		if rotatedSuffix.MatchString(strings.TrimPrefix(m, l.Path)) {
+			files = append(files, m)
+		}
+	}
+	// Glob returns names sorted, and the suffix sorts by time
+	if _, err := os.Stat(l.Path); err == nil {
+		files = append(files, l.Path)
+	}
+	return files, nil
+}
+
+// globEscape escapes glob metacharacters in a path.
+func globEscape(path string) string {
+	var b strings.Builder
+	for _, c := range path {
+		if strings.ContainsRune(`*?[\`, c) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// Append chains the record to the end of the log and appends it as a JSON line,
+// rotating the current file first when it is too large or too old.
+func (l *Log) Append(record *Record) error {
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0o700); err != nil {
+		return err
+	}
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	h, err := l.head()
+	if err != nil {
+		return err
+	}
+	record.Seq = h.seq + 1
+	record.PrevHash = h.hash
+	if record.Hash, err = record.computeHash(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if err := l.rotate(record.Time, int64(len(data))); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return l.writeHead(head{seq: record.Seq, hash: record.Hash})
+}
+
+// lock serializes writers with a lock file, breaking locks left behind by crashed processes.
+func (l *Log) lock() (func(), error) {
+	path := l.Path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("audit log is locked by %s", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// head returns the end of the chain from the head file,
+// or from the last record when the head file is missing, e.g. for logs written before chaining.
+func (l *Log) head() (head, error) {
+	data, err := os.ReadFile(l.headPath())
+	if err == nil {
+		return parseHead(data)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return head{}, err
+	}
+
+	records, err := l.Read()
+	if err != nil || len(records) == 0 {
+		return head{}, err
+	}
+	last := records[len(records)-1]
+	return head{seq: last.Seq, hash: last.Hash}, nil
+}
+
+func parseHead(data []byte) (head, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return head{}, errors.New("invalid audit log head")
+	}
+	seq, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return head{}, fmt.Errorf("invalid audit log head: %w", err)
+	}
+	return head{seq: seq, hash: fields[1]}, nil
+}
+
+func (l *Log) writeHead(h head) error {
+	tmp := l.headPath() + ".tmp"
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%d %s\n", h.seq, h.hash)), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.headPath())
+}
+
+// rotate moves the current file aside when appending size bytes at now would exceed a limit.
+// The chain continues in the new file.
+func (l *Log) rotate(now time.Time, size int64) error {
+	info, err := os.Stat(l.Path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && info.Size() == 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	rotate := l.MaxSize > 0 && info.Size()+size > l.MaxSize
+	if !rotate && l.MaxAge > 0 {
+		first, err := firstRecord(l.Path)
+		if err != nil {
+			return err
+		}
+		rotate = first != nil && now.Sub(first.Time) > l.MaxAge
+	}
+	if !rotate {
+		return nil
+	}
+	// never overwrite a rotated file, even when the clock goes backwards
+	for {
+		rotated := l.Path + "." + now.UTC().Format(rotatedFormat)
+		if _, err := os.Stat(rotated); errors.Is(err, os.ErrNotExist) {
+			return os.Rename(l.Path, rotated)
+		}
+		now = now.Add(time.Nanosecond)
+	}
+}
+
+func firstRecord(path string) (*Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil, nil
+	}
+	var r Record
+	if err := json.Unmarshal(line, &r); err != nil {
+		return nil, fmt.Errorf("%s:1: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Read reads every record of the log, oldest first. A missing log has no records.
+func (l *Log) Read() ([]Record, error) {
+	var records []Record
+	err := l.scan(func(_ string, _ int, r *Record) error {
+		records = append(records, *r)
+		return nil
+	})
+	return records, err
+}
+
+// scan calls fn for every record of the log, oldest first.
+func (l *Log) scan(fn func(file string, line int, r *Record) error) error {
+	files, err := l.Files()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := scanFile(file, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanFile(file string, fn func(file string, line int, r *Record) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return fmt.Errorf("%s:%d: %w", file, line, err)
+		}
+		if err := fn(file, line, &r); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Verify checks the chain across every file of the log and against the head file,
+// and returns the number of records. Edited, inserted, removed and reordered records
+// break the chain; truncation leaves the chain short of the head.
+func (l *Log) Verify() (int, error) {
+	var prev head
+	count := 0
+	err := l.scan(func(file string, line int, r *Record) error {
+		hash, err := r.computeHash()
+		if err != nil {
+			return err
+		}
+		switch {
+		case r.Seq != prev.seq+1:
+			return fmt.Errorf("%s:%d: expected record %d, found %d", file, line, prev.seq+1, r.Seq)
+		case r.PrevHash != prev.hash:
+			return fmt.Errorf("%s:%d: record %d does not follow the previous record", file, line, r.Seq)
+		case r.Hash != hash:
+			return fmt.Errorf("%s:%d: record %d was modified", file, line, r.Seq)
+		}
+		prev = head{seq: r.Seq, hash: r.Hash}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	data, err := os.ReadFile(l.headPath())
+	switch {
+	case errors.Is(err, os.ErrNotExist) && count == 0:
+		return 0, nil
+	case errors.Is(err, os.ErrNotExist):
+		return count, fmt.Errorf("%s is missing", l.headPath())
+	case err != nil:
+		return count, err
+	}
+	h, err := parseHead(data)
+	if err != nil {
+		return count, err
+	}
+	switch {
+	case h.seq > prev.seq:
+		return count, fmt.Errorf("log ends at record %d, but %s expects record %d: the log was truncated", prev.seq, l.headPath(), h.seq)
+	case h != prev:
+		return count, fmt.Errorf("log ends at record %d, which does not match %s", prev.seq, l.headPath())
+	}
+	return count, nil
+}
@@ -0,0 +1,206 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeRecords appends n records an hour apart, starting at start.
+func writeRecords(t *testing.T, log *Log, start time.Time, n int) {
+	t.Helper()
+	for i := range n {
+		r := &Record{
+			Time:      start.Add(time.Duration(i) * time.Hour),
+			OSUser:    "alice",
+			Context:   "prod",
+			Namespace: "payments",
+			Args:      []string{"delete", "pod", "nginx"},
+			Decision:  DecisionOverridden,
+			ExitCode:  intPtr(0),
+		}
+		if err := log.Append(r); err != nil {
+			t.Fatalf("failed to append: %v", err)
+		}
+	}
+}
+
+var start = time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+func TestLog_Verify(t *testing.T) {
+	log := &Log{Path: filepath.Join(t.TempDir(), "audit.log")}
+
+	if count, err := log.Verify(); err != nil || count != 0 {
+		t.Fatalf("expected an empty log to verify, got %d (%v)", count, err)
+	}
+
+	writeRecords(t, log, start, 3)
+	if count, err := log.Verify(); err != nil || count != 3 {
+		t.Fatalf("expected 3 verified records, got %d (%v)", count, err)
+	}
+}
+
+func TestLog_Verify_Tampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines []string) []string
+		err    string
+	}{
+		{
+			name: "edited record",
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `"decision":"overridden"`, `"decision":"confirmed"`, 1)
+				return lines
+			},
+			err: "record 2 was modified",
+		},
+		{
+			name: "removed record",
+			tamper: func(lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			},
+			err: "expected record 2, found 3",
+		},
+		{
+			name: "reordered records",
+			tamper: func(lines []string) []string {
+				lines[1], lines[2] = lines[2], lines[1]
+				return lines
+			},
+			err: "expected record 2, found 3",
+		},
+		{
+			name: "truncated log",
+			tamper: func(lines []string) []string {
+				return lines[:2]
+			},
+			err: "the log was truncated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &Log{Path: filepath.Join(t.TempDir(), "audit.log")}
+			writeRecords(t, log, start, 3)
+
+			data, err := os.ReadFile(log.Path)
+			if err != nil {
+				t.Fatalf("failed to read log: %v", err)
+			}
+			lines := tt.tamper(strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"))
+			if err := os.WriteFile(log.Path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+				t.Fatalf("failed to write log: %v", err)
+			}
+
+			_, err = log.Verify()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestLog_Verify_MissingHead(t *testing.T) {
+	log := &Log{Path: filepath.Join(t.TempDir(), "audit.log")}
+	writeRecords(t, log, start, 2)
+
+	if err := os.Remove(log.Path + ".head"); err != nil {
+		t.Fatalf("failed to remove head: %v", err)
+	}
+	if _, err := log.Verify(); err == nil {
+		t.Error("expected error for a missing head")
+	}
+
+	// appending recovers the head from the last record
+	writeRecords(t, log, start.Add(2*time.Hour), 1)
+	if count, err := log.Verify(); err != nil || count != 3 {
+		t.Errorf("expected 3 verified records, got %d (%v)", count, err)
+	}
+}
+
+func TestLog_RotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	log := &Log{Path: filepath.Join(dir, "audit.log"), MaxSize: 1000}
+	writeRecords(t, log, start, 10)
+
+	files, err := log.Files()
+	if err != nil {
+		t.Fatalf("failed to list files: %v", err)
+	}
+	if len(files) < 3 {
+		t.Fatalf("expected the log to be rotated, got %q", files)
+	}
+	if files[len(files)-1] != log.Path {
+		t.Errorf("expected the current file last, got %q", files)
+	}
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			t.Fatalf("failed to stat %s: %v", f, err)
+		}
+		if info.Size() > log.MaxSize {
+			t.Errorf("%s exceeds the size limit: %d bytes", f, info.Size())
+		}
+	}
+
+	if count, err := log.Verify(); err != nil || count != 10 {
+		t.Errorf("expected the chain to span rotated files, got %d (%v)", count, err)
+	}
+	records, err := log.Read()
+	if err != nil || len(records) != 10 || records[9].Seq != 10 {
+		t.Errorf("expected every record in order, got %d (%v)", len(records), err)
+	}
+
+	// removing a rotated file breaks the chain
+	if err := os.Remove(files[1]); err != nil {
+		t.Fatalf("failed to remove %s: %v", files[1], err)
+	}
+	if _, err := log.Verify(); err == nil {
+		t.Error("expected error for a removed file")
+	}
+}
+
+func TestLog_RotateByAge(t *testing.T) {
+	log := &Log{Path: filepath.Join(t.TempDir(), "audit.log"), MaxAge: 24 * time.Hour}
+	writeRecords(t, log, start, 2)
+	writeRecords(t, log, start.Add(48*time.Hour), 1)
+
+	files, err := log.Files()
+	if err != nil {
+		t.Fatalf("failed to list files: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected one rotated file, got %q", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", files[0], err)
+	}
+	if n := bytes.Count(data, []byte("\n")); n != 2 {
+		t.Errorf("expected 2 records in the rotated file, got %d", n)
+	}
+	if count, err := log.Verify(); err != nil || count != 3 {
+		t.Errorf("expected 3 verified records, got %d (%v)", count, err)
+	}
+}
+
+func TestLog_Lock(t *testing.T) {
+	log := &Log{Path: filepath.Join(t.TempDir(), "audit.log")}
+
+	// a lock left behind by a crashed process is broken
+	lock := log.Path + ".lock"
+	if err := os.WriteFile(lock, nil, 0o600); err != nil {
+		t.Fatalf("failed to write lock: %v", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatalf("failed to age lock: %v", err)
+	}
+	writeRecords(t, log, start, 1)
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Errorf("expected the lock to be released, got %v", err)
+	}
+}
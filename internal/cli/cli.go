@@ -2,12 +2,18 @@
 package cli
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/sivchari/kubectl-guard/internal/audit"
 	"github.com/sivchari/kubectl-guard/internal/config"
 	"github.com/sivchari/kubectl-guard/internal/guard"
+	"github.com/sivchari/kubectl-guard/internal/kubeconfig"
 )
 
 const usage = `kubectl-guard - Kubernetes context protection plugin
@@ -16,21 +22,36 @@ Usage:
   kubectl guard <command> [options]
 
 Commands:
-  guard <context> [--namespace=<ns>]  Protect a context
-  unguard <context>                   Remove protection from a context
+  guard <context> [--namespace=<ns>] [--exclude-namespace=<ns>]
+                                      Protect a context
+  guard --pattern=<pattern>           Protect contexts matching a glob or ^regex
+  unguard <context|pattern> [--for=<duration>]
+                                      Remove protection from a context, for a while with --for
+  relax <context|pattern> --for=<duration>
+                                      Suspend protection until the duration has passed
   list                                List protected contexts and current status
   exec -- <kubectl args>              Execute kubectl with protection check
+  log [--since=<time>] [--until=<time>] [--context=<context>] [--decision=<decision>]
+                                      Show the audit log of guarded commands
+  log verify                          Verify the audit log's hash chain
 
 Examples:
   kubectl guard guard prod-cluster
   kubectl guard guard prod-cluster --namespace=production
+  kubectl guard guard --pattern='gke_acme-prod_*'
+  kubectl guard guard prod-cluster --exclude-namespace='dev-*,sandbox'
   kubectl guard unguard prod-cluster
+  kubectl guard unguard prod-cluster --for 30m
   kubectl guard list
   kubectl guard exec -- delete pod nginx
+  kubectl guard log --since=24h --context='prod-*' --decision=overridden
+  kubectl guard log verify
 
 Options:
-  --force    Force execution on protected context
-  --help     Show help
+  --guard-override    Execute on protected context (or set KUBECTL_GUARD_OVERRIDE=1)
+  --guard-reason=<reason>
+                      Reason for overriding the guard (or set KUBECTL_GUARD_REASON)
+  --help              Show help
 `
 
 // Run executes the CLI.
@@ -51,10 +72,14 @@ func Run(args []string) int {
 		return runGuard(cfg, args[1:])
 	case "unguard":
 		return runUnguard(cfg, args[1:])
+	case "relax":
+		return runRelax(cfg, args[1:])
 	case "list":
 		return runList(cfg)
 	case "exec":
 		return runExec(cfg, args[1:])
+	case "log":
+		return runLog(cfg, args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "unknown command: %s\n", args[0])
 		fmt.Print(usage)
@@ -63,45 +88,82 @@ func Run(args []string) int {
 }
 
 func runGuard(cfg *config.Config, args []string) int {
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "context name is required")
-		return 1
-	}
-
-	context := args[0]
-	var namespaces []string
+	var context, pattern string
+	var namespaces, excludes []string
+	var setExcludes bool
 
-	for _, arg := range args[1:] {
-		if strings.HasPrefix(arg, "--namespace=") {
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--namespace="):
 			ns := strings.TrimPrefix(arg, "--namespace=")
 			namespaces = strings.Split(ns, ",")
-		} else if strings.HasPrefix(arg, "-n=") {
+		case strings.HasPrefix(arg, "-n="):
 			ns := strings.TrimPrefix(arg, "-n=")
 			namespaces = strings.Split(ns, ",")
+		case strings.HasPrefix(arg, "--exclude-namespace="):
+			ns := strings.TrimPrefix(arg, "--exclude-namespace=")
+			excludes = nil
+			if ns != "" {
+				excludes = strings.Split(ns, ",")
+			}
+			setExcludes = true
+		case strings.HasPrefix(arg, "--pattern="):
+			pattern = strings.TrimPrefix(arg, "--pattern=")
+		case !strings.HasPrefix(arg, "-") && context == "":
+			context = arg
 		}
 	}
 
-	cfg.AddContext(context, namespaces)
-	if err := cfg.Save(); err != nil {
-		fmt.Fprintf(os.Stderr, "failed to save config: %v\n", err)
+	if context == "" && pattern == "" {
+		fmt.Fprintln(os.Stderr, "context name or --pattern is required")
+		return 1
+	}
+	if context != "" && pattern != "" {
+		fmt.Fprintln(os.Stderr, "context name and --pattern are mutually exclusive")
 		return 1
 	}
 
-	if len(namespaces) > 0 {
-		fmt.Printf("guarded %s (namespaces: %s)\n", context, strings.Join(namespaces, ", "))
+	if pattern != "" {
+		if err := cfg.AddPattern(pattern, namespaces); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		context = pattern
 	} else {
-		fmt.Printf("guarded %s (all namespaces)\n", context)
+		cfg.AddContext(context, namespaces)
+	}
+	// Guarding a relaxed context again ends the relaxation
+	cfg.Unrelax(context)
+	// Guarding again without --exclude-namespace keeps the existing excludes
+	if setExcludes {
+		if err := cfg.SetExcludeNamespaces(context, excludes); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
 	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := cfg.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to save config: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("guarded %s (%s)\n", context, cfg.Entry(context).NamespaceRule())
 	return 0
 }
 
 func runUnguard(cfg *config.Config, args []string) int {
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "context name is required")
+	context, duration, err := parseRelaxArgs(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
+	if duration > 0 {
+		return relax(cfg, context, duration)
+	}
 
-	context := args[0]
 	if !cfg.RemoveContext(context) {
 		fmt.Fprintf(os.Stderr, "%s is not guarded\n", context)
 		return 1
@@ -116,29 +178,144 @@ func runUnguard(cfg *config.Config, args []string) int {
 	return 0
 }
 
+func runRelax(cfg *config.Config, args []string) int {
+	context, duration, err := parseRelaxArgs(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if duration == 0 {
+		fmt.Fprintln(os.Stderr, "--for is required")
+		return 1
+	}
+	return relax(cfg, context, duration)
+}
+
+// parseRelaxArgs parses "<context> [--for=<duration>]"; the duration is zero without --for.
+func parseRelaxArgs(args []string) (string, time.Duration, error) {
+	var context, value string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case strings.HasPrefix(arg, "--for="):
+			value = strings.TrimPrefix(arg, "--for=")
+		case arg == "--for" && i+1 < len(args):
+			value = args[i+1]
+			i++
+		case !strings.HasPrefix(arg, "-") && context == "":
+			context = arg
+		default:
+			return "", 0, fmt.Errorf("unknown option: %s", arg)
+		}
+	}
+	if context == "" {
+		return "", 0, errors.New("context name is required")
+	}
+	if value == "" {
+		return context, 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return "", 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return context, duration, nil
+}
+
+// relax suspends the guard of the context for the duration.
+func relax(cfg *config.Config, context string, duration time.Duration) int {
+	until, err := cfg.Relax(context, duration)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := cfg.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to save config: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("unguarded %s until %s\n", context, until.Local().Format(time.RFC3339))
+	return 0
+}
+
+// formatRemaining describes the time left until a relaxation expires, e.g. "29m59s".
+func formatRemaining(r *config.Relaxation, now time.Time) string {
+	return r.Remaining(now).Round(time.Second).String()
+}
+
 func runList(cfg *config.Config) int {
-	ctx, _ := guard.GetCurrentContext()
+	ctx, _ := guard.GetCurrentContext("")
+	now := cfg.Now()
+
+	// Concrete contexts are only needed to show what patterns match
+	var contexts []string
+	if kc, err := kubeconfig.Load(""); err == nil {
+		contexts = kc.ContextNames()
+	}
 
 	if len(cfg.GuardedContexts) == 0 {
 		fmt.Println("no guarded contexts")
 	} else {
 		fmt.Println("guarded contexts:")
-		for _, gc := range cfg.GuardedContexts {
+		current := cfg.Entry(ctx)
+		for i := range cfg.GuardedContexts {
+			gc := &cfg.GuardedContexts[i]
 			marker := " "
-			if gc.Name == ctx {
+			if gc == current {
 				marker = "*"
 			}
-			if len(gc.Namespaces) > 0 {
-				fmt.Printf(" %s %s (namespaces: %s)\n", marker, gc.Name, strings.Join(gc.Namespaces, ", "))
-			} else {
-				fmt.Printf(" %s %s (all namespaces)\n", marker, gc.Name)
+			name := gc.Name
+			if gc.Pattern != "" {
+				name = "pattern " + gc.Pattern
 			}
+			fmt.Printf(" %s %s (%s)\n", marker, name, gc.NamespaceRule())
+			fmt.Printf("     blocks: %s\n", gc.CommandRule())
+			for _, rule := range gc.Rules {
+				fmt.Printf("     rule: %s\n", rule.String())
+			}
+			if rule := gc.BlastRadiusRule(); rule != "" {
+				fmt.Printf("     blast radius: %s\n", rule)
+			}
+			for i := range gc.Schedules {
+				state := "not guarding now"
+				if gc.Schedules[i].Guards(now) {
+					state = "guarding now"
+				}
+				fmt.Printf("     schedule: %s (%s)\n", gc.Schedules[i].String(), state)
+			}
+			if gc.Pattern != "" {
+				fmt.Printf("     matches: %s\n", formatMatches(cfg, gc, contexts))
+			}
+			for _, r := range cfg.EntryRelaxations(gc) {
+				fmt.Printf("     relaxed: %s, guarded again in %s\n", r.Context, formatRemaining(&r, now))
+			}
+		}
+	}
+
+	if len(cfg.ProtectedResources) > 0 {
+		fmt.Println("protected resources:")
+		for _, p := range cfg.ProtectedResources {
+			fmt.Printf("   %s\n", p.String())
+		}
+	}
+
+	if len(cfg.FreezeCalendars) > 0 {
+		fmt.Println("freeze calendars:")
+		for i := range cfg.FreezeCalendars {
+			fc := &cfg.FreezeCalendars[i]
+			fmt.Printf("   %s (contexts: %s)\n", fc.File, strings.Join(fc.Contexts, ", "))
+			fmt.Printf("     %s\n", formatFreeze(fc, now))
 		}
 	}
 
 	fmt.Println()
 	if ctx != "" {
-		if cfg.IsGuarded(ctx) {
+		if f, err := cfg.ActiveFreeze(ctx, now); err == nil && f != nil {
+			fmt.Printf("current: %s (frozen: %s)\n", ctx, f.String())
+		} else if r := cfg.Relaxation(ctx); r != nil {
+			fmt.Printf("current: %s (relaxed, guarded again in %s)\n", ctx, formatRemaining(r, now))
+		} else if gc := cfg.Lookup(ctx); gc != nil && !gc.GuardsAt("", now) {
+			fmt.Printf("current: %s (guarded, but not at this time)\n", ctx)
+		} else if gc != nil {
 			fmt.Printf("current: %s (guarded)\n", ctx)
 		} else {
 			fmt.Printf("current: %s (not guarded)\n", ctx)
@@ -147,6 +324,99 @@ func runList(cfg *config.Config) int {
 	return 0
 }
 
+// formatFreeze describes the freeze of the calendar in effect at the time, or else the next one.
+func formatFreeze(fc *config.FreezeCalendar, now time.Time) string {
+	freezes, err := fc.Freezes(now)
+	if err != nil {
+		return err.Error()
+	}
+	f := config.CurrentFreeze(freezes, now)
+	switch {
+	case f == nil:
+		return "no upcoming freeze"
+	case f.Start.After(now):
+		return "next freeze: " + f.String()
+	default:
+		return "active freeze: " + f.String()
+	}
+}
+
+// formatMatches lists the kubeconfig contexts the pattern entry applies to.
+// Contexts with their own exact entry, or claimed by an earlier pattern, are not listed.
+func formatMatches(cfg *config.Config, gc *config.GuardedContext, contexts []string) string {
+	var matches []string
+	for _, name := range contexts {
+		if cfg.Entry(name) == gc {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) == 0 {
+		return "(no contexts)"
+	}
+	return strings.Join(matches, ", ")
+}
+
+func runLog(cfg *config.Config, args []string) int {
+	if len(args) > 0 && args[0] == "verify" {
+		return runLogVerify(cfg)
+	}
+
+	var filter audit.Filter
+	now := time.Now()
+	for _, arg := range args {
+		var err error
+		switch {
+		case strings.HasPrefix(arg, "--since="):
+			filter.Since, err = audit.ParseTime(strings.TrimPrefix(arg, "--since="), now)
+		case strings.HasPrefix(arg, "--until="):
+			filter.Until, err = audit.ParseTime(strings.TrimPrefix(arg, "--until="), now)
+		case strings.HasPrefix(arg, "--context="):
+			filter.Context = strings.TrimPrefix(arg, "--context=")
+		case strings.HasPrefix(arg, "--decision="):
+			filter.Decision, err = audit.ParseDecision(strings.TrimPrefix(arg, "--decision="))
+		default:
+			err = fmt.Errorf("unknown option: %s", arg)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	log, err := audit.Open(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to locate audit log: %v\n", err)
+		return 1
+	}
+	records, err := log.Read()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read audit log: %v\n", err)
+		return 1
+	}
+
+	for i := range records {
+		if filter.Matches(&records[i]) {
+			fmt.Println(records[i].String())
+		}
+	}
+	return 0
+}
+
+func runLogVerify(cfg *config.Config) int {
+	log, err := audit.Open(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to locate audit log: %v\n", err)
+		return 1
+	}
+	count, err := log.Verify()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "audit log verification failed after %d records: %v\n", count, err)
+		return 1
+	}
+	fmt.Printf("audit log verified: %d records\n", count)
+	return 0
+}
+
 func runExec(cfg *config.Config, args []string) int {
 	// Remove "--" separator if present
 	if len(args) > 0 && args[0] == "--" {
@@ -160,10 +430,39 @@ func runExec(cfg *config.Config, args []string) int {
 
 	g := guard.New(cfg)
 
-	// Check for --force flag
-	forceMode := guard.HasForceFlag(args)
-	if forceMode {
-		args = guard.RemoveForceFlag(args)
+	// The override flag is for the guard only and never reaches kubectl
+	argv := args
+	override := guard.HasOverride(args)
+	reason := guard.GetOverrideReason(args)
+	args = guard.RemoveReasonFlag(guard.RemoveOverrideFlag(args))
+
+	// kubectl reads "-f -" from stdin, so it is buffered for the check and replayed to kubectl.
+	// When nothing may guard the command, stdin is left to kubectl untouched.
+	var stdin io.Reader
+	inspect := false
+	if guard.ReadsStdin(args) {
+		var err error
+		if inspect, err = g.Inspects(args); err != nil {
+			fmt.Fprintf(os.Stderr, "check failed: %v\n", err)
+			return 1
+		}
+	}
+	if inspect {
+		data, err := guard.ReadStdin(os.Stdin)
+		switch {
+		case errors.Is(err, guard.ErrStdinTooLarge) && override:
+			// not inspected, but the bytes read so far still reach kubectl
+			stdin = io.MultiReader(bytes.NewReader(data), os.Stdin)
+		case errors.Is(err, guard.ErrStdinTooLarge):
+			fmt.Fprintf(os.Stderr, "%v and cannot be inspected\nSave it to a file and use -f <file>, or use %s to execute without inspection.\n", err, guard.OverrideFlag)
+			return 1
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "failed to read stdin: %v\n", err)
+			return 1
+		default:
+			g.SetStdin(data)
+			stdin = bytes.NewReader(data)
+		}
 	}
 
 	result, err := g.Check(args)
@@ -172,17 +471,69 @@ func runExec(cfg *config.Config, args []string) int {
 		return 1
 	}
 
-	if result.Blocked && !forceMode {
+	// Only non-trivial decisions are recorded
+	var decision audit.Decision
+	switch result.Action {
+	case config.ActionAllow:
+	case config.ActionWarn:
+		fmt.Fprintln(os.Stderr, result.Message)
+		decision = audit.DecisionWarned
+	case config.ActionConfirm, config.ActionBlock:
+		if override {
+			if !overrideReason(prompter, result, reason, os.Stderr) {
+				record(cfg, result, argv, audit.DecisionBlocked, nil)
+				return 1
+			}
+			fmt.Fprintf(os.Stderr, "executing %s on %s with %s\n", result.Command, result.Context, guard.OverrideFlag)
+			decision = audit.DecisionOverridden
+			break
+		}
+		if result.Action == config.ActionBlock {
+			fmt.Fprintln(os.Stderr, result.Message)
+			record(cfg, result, argv, audit.DecisionBlocked, nil)
+			return 1
+		}
+		decision = confirmCommand(prompter, cfg, result, os.Stderr)
+		if decision == audit.DecisionAborted {
+			record(cfg, result, argv, decision, nil)
+			return 1
+		}
+	case config.ActionDeny:
 		fmt.Fprintln(os.Stderr, result.Message)
+		record(cfg, result, argv, audit.DecisionDenied, nil)
 		return 1
 	}
 
-	if forceMode && result.Blocked {
-		fmt.Fprintf(os.Stderr, "executing %s on %s with --force\n", result.Command, result.Context)
+	code, err := guard.ExecKubectl(args, stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to execute kubectl: %v\n", err)
+		code = 1
+	}
+	if decision != "" {
+		record(cfg, result, argv, decision, &code)
 	}
+	return code
+}
 
-	if err := guard.ExecKubectl(args); err != nil {
-		return 1
+// record appends the decision to the audit log, without the credentials given on the command line.
+// A failure is reported but does not change the outcome of the command.
+func record(cfg *config.Config, result *guard.CheckResult, argv []string, decision audit.Decision, exitCode *int) {
+	log, err := audit.Open(cfg)
+	if err == nil {
+		err = log.Append(&audit.Record{
+			Time:           time.Now().UTC(),
+			OSUser:         audit.CurrentUser(),
+			KubeUser:       result.User,
+			Context:        result.Context,
+			Namespace:      result.Namespace,
+			Args:           guard.RedactArgs(argv),
+			Decision:       decision,
+			Reason:         result.Reason,
+			ExitCode:       exitCode,
+			OverrideReason: result.OverrideReason,
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write audit log: %v\n", err)
 	}
-	return 0
 }
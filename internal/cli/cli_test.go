@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sivchari/kubectl-guard/internal/config"
+)
+
+func TestRunGuard_ExcludeNamespaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{name: "kept when not given", args: []string{"prod"}, expected: []string{"dev-*"}},
+		{name: "replaced when given", args: []string{"prod", "--exclude-namespace=sandbox"}, expected: []string{"sandbox"}},
+		{name: "cleared when empty", args: []string{"prod", "--exclude-namespace="}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			cfg := &config.Config{GuardedContexts: []config.GuardedContext{
+				{Name: "prod", ExcludeNamespaces: []string{"dev-*"}},
+			}}
+			if code := runGuard(cfg, tt.args); code != 0 {
+				t.Fatalf("expected exit code 0, got %d", code)
+			}
+			if got := cfg.Entry("prod").ExcludeNamespaces; !slices.Equal(got, tt.expected) {
+				t.Errorf("expected excludes %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
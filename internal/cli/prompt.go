@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/sivchari/kubectl-guard/internal/audit"
+	"github.com/sivchari/kubectl-guard/internal/config"
+	"github.com/sivchari/kubectl-guard/internal/guard"
+)
+
+// ErrNotTerminal is returned by a Prompter when there is no terminal to ask.
+var ErrNotTerminal = errors.New("no terminal to ask")
+
+// Prompter asks the user to confirm a command.
+type Prompter interface {
+	// Confirm shows the message, asks to type the answer and reports whether it was typed.
+	Confirm(message, answer string) (bool, error)
+	// Ask asks the question and returns the answer.
+	Ask(question string) (string, error)
+}
+
+// prompter confirms commands in runExec.
+var prompter Prompter = &terminalPrompter{open: openTerminal, out: os.Stderr}
+
+// terminalPrompter prompts on the controlling terminal rather than stdin,
+// so that commands reading manifests from stdin can be confirmed too.
+type terminalPrompter struct {
+	open func() (io.ReadCloser, error)
+	out  io.Writer
+}
+
+// Confirm implements Prompter.
+func (p *terminalPrompter) Confirm(message, answer string) (bool, error) {
+	fmt.Fprintln(p.out, message)
+	line, err := p.readLine(fmt.Sprintf("\nType %q to execute: ", answer))
+	if err != nil {
+		return false, err
+	}
+	return line == answer, nil
+}
+
+// Ask implements Prompter.
+func (p *terminalPrompter) Ask(question string) (string, error) {
+	return p.readLine(question)
+}
+
+// readLine shows the prompt and reads a line from the terminal.
+func (p *terminalPrompter) readLine(prompt string) (string, error) {
+	in, err := p.open()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNotTerminal, err)
+	}
+	defer in.Close()
+
+	fmt.Fprint(p.out, prompt)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// openTerminal opens the controlling terminal, which fails when there is none, e.g. in CI.
+func openTerminal() (io.ReadCloser, error) {
+	name := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		name = "CONIN$"
+	}
+	return os.Open(name)
+}
+
+// confirmCommand asks to type the context or namespace name before a command needing confirmation runs.
+// Without a terminal, the command is refused unless the config fails open.
+func confirmCommand(p Prompter, cfg *config.Config, result *guard.CheckResult, out io.Writer) audit.Decision {
+	ok, err := p.Confirm(result.Message, confirmAnswer(cfg, result))
+	switch {
+	case errors.Is(err, ErrNotTerminal) && cfg.ConfirmFailOpen:
+		fmt.Fprintf(out, "\n%v, executing without confirmation\n", err)
+		return audit.DecisionUnconfirmed
+	case errors.Is(err, ErrNotTerminal):
+		fmt.Fprintf(out, "\n%v, refusing to execute without confirmation\n", err)
+		return audit.DecisionAborted
+	case err != nil:
+		fmt.Fprintf(out, "confirmation failed: %v\n", err)
+		return audit.DecisionAborted
+	case !ok:
+		fmt.Fprintln(out, "aborted")
+		return audit.DecisionAborted
+	}
+	return audit.DecisionConfirmed
+}
+
+// overrideReason records the reason for overriding the guard, asking for it when the entry
+// requires one and none is given. It reports whether the override may proceed.
+func overrideReason(p Prompter, result *guard.CheckResult, reason string, out io.Writer) bool {
+	if reason == "" && result.RequireReason {
+		answer, err := p.Ask(fmt.Sprintf("Reason for overriding the guard on %s: ", result.Context))
+		if err != nil {
+			fmt.Fprintf(out, "%v (%v), use %s=<reason>\n", guard.ErrReasonRequired, err, guard.ReasonFlag)
+			return false
+		}
+		reason = answer
+	}
+	if err := result.SetOverrideReason(reason); err != nil {
+		fmt.Fprintln(out, err)
+		return false
+	}
+	return true
+}
+
+// confirmAnswer returns the name to type: the context, or the namespace when the entry asks for it
+// and the command acts on a single namespace.
+func confirmAnswer(cfg *config.Config, result *guard.CheckResult) string {
+	gc := cfg.Lookup(result.Context)
+	if gc != nil && gc.ConfirmWith == config.ConfirmWithNamespace && result.Scope == guard.ScopeNamespace {
+		return result.Namespace
+	}
+	return result.Context
+}
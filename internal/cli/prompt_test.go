@@ -0,0 +1,267 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sivchari/kubectl-guard/internal/audit"
+	"github.com/sivchari/kubectl-guard/internal/config"
+	"github.com/sivchari/kubectl-guard/internal/guard"
+)
+
+func TestTerminalPrompter_Confirm(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		terminal bool
+		expected bool
+		err      error
+	}{
+		{name: "typed the name", input: "prod\n", terminal: true, expected: true},
+		{name: "surrounding spaces", input: "  prod \n", terminal: true, expected: true},
+		{name: "no trailing newline", input: "prod", terminal: true, expected: true},
+		{name: "wrong name", input: "prd\n", terminal: true, expected: false},
+		{name: "yes is not enough", input: "y\n", terminal: true, expected: false},
+		{name: "no input", input: "", terminal: true, expected: false},
+		{name: "not a terminal", input: "prod\n", terminal: false, expected: false, err: ErrNotTerminal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := &terminalPrompter{open: fakeTerminal(tt.input, tt.terminal), out: &out}
+			ok, err := p.Confirm("confirmation required", "prod")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if ok != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, ok)
+			}
+			if tt.terminal && !strings.Contains(out.String(), `Type "prod" to execute`) {
+				t.Errorf("expected the prompt, got %q", out.String())
+			}
+		})
+	}
+}
+
+// fakeTerminal returns a terminal opener reading the input, or failing when there is no terminal.
+func fakeTerminal(input string, terminal bool) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		if !terminal {
+			return nil, errors.New("open /dev/tty: no such device or address")
+		}
+		return io.NopCloser(strings.NewReader(input)), nil
+	}
+}
+
+type fakePrompter struct {
+	answer string // what the user types
+	err    error
+	asked  string // what the user was asked to type
+}
+
+func (p *fakePrompter) Confirm(_, answer string) (bool, error) {
+	p.asked = answer
+	if p.err != nil {
+		return false, p.err
+	}
+	return p.answer == answer, nil
+}
+
+func (p *fakePrompter) Ask(question string) (string, error) {
+	p.asked = question
+	if p.err != nil {
+		return "", p.err
+	}
+	return p.answer, nil
+}
+
+func TestTerminalPrompter_Ask(t *testing.T) {
+	var out bytes.Buffer
+	p := &terminalPrompter{open: fakeTerminal("  INC-42 \n", true), out: &out}
+	answer, err := p.Ask("Reason: ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if answer != "INC-42" {
+		t.Errorf("expected %q, got %q", "INC-42", answer)
+	}
+	if out.String() != "Reason: " {
+		t.Errorf("expected the question, got %q", out.String())
+	}
+
+	p.open = fakeTerminal("", false)
+	if _, err := p.Ask("Reason: "); !errors.Is(err, ErrNotTerminal) {
+		t.Errorf("expected %v, got %v", ErrNotTerminal, err)
+	}
+}
+
+func TestOverrideReason(t *testing.T) {
+	required := func() *guard.CheckResult {
+		return &guard.CheckResult{Context: "prod", RequireReason: true, ReasonPattern: `^(INC|CHG)-[0-9]+$`}
+	}
+
+	tests := []struct {
+		name     string
+		result   *guard.CheckResult
+		reason   string
+		prompter *fakePrompter
+		expected bool
+		recorded string
+	}{
+		{
+			name:     "not required",
+			result:   &guard.CheckResult{Context: "prod"},
+			prompter: &fakePrompter{},
+			expected: true,
+		},
+		{
+			name:     "not required, given",
+			result:   &guard.CheckResult{Context: "prod"},
+			reason:   "rollback",
+			prompter: &fakePrompter{},
+			expected: true,
+			recorded: "rollback",
+		},
+		{
+			name:     "given with the flag",
+			result:   required(),
+			reason:   "INC-42",
+			prompter: &fakePrompter{},
+			expected: true,
+			recorded: "INC-42",
+		},
+		{
+			name:     "asked for",
+			result:   required(),
+			prompter: &fakePrompter{answer: "CHG-7"},
+			expected: true,
+			recorded: "CHG-7",
+		},
+		{
+			name:     "not matching the pattern",
+			result:   required(),
+			reason:   "because",
+			prompter: &fakePrompter{},
+			expected: false,
+		},
+		{
+			name:     "empty answer",
+			result:   required(),
+			prompter: &fakePrompter{answer: ""},
+			expected: false,
+		},
+		{
+			name:     "not a terminal",
+			result:   required(),
+			prompter: &fakePrompter{err: ErrNotTerminal},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if ok := overrideReason(tt.prompter, tt.result, tt.reason, &out); ok != tt.expected {
+				t.Errorf("expected %v, got %v (output %q)", tt.expected, ok, out.String())
+			}
+			if tt.result.OverrideReason != tt.recorded {
+				t.Errorf("expected reason %q, got %q", tt.recorded, tt.result.OverrideReason)
+			}
+			if (tt.reason == "" && tt.result.RequireReason) != (tt.prompter.asked != "") {
+				t.Errorf("unexpected question %q", tt.prompter.asked)
+			}
+		})
+	}
+}
+
+func TestConfirmCommand(t *testing.T) {
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{Name: "prod"},
+			{Name: "staging", ConfirmWith: config.ConfirmWithNamespace},
+		},
+	}
+	failOpen := &config.Config{GuardedContexts: cfg.GuardedContexts, ConfirmFailOpen: true}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		result   *guard.CheckResult
+		prompter *fakePrompter
+		asked    string
+		expected audit.Decision
+	}{
+		{
+			name:     "context name",
+			cfg:      cfg,
+			result:   &guard.CheckResult{Context: "prod", Namespace: "payments", Scope: guard.ScopeNamespace},
+			prompter: &fakePrompter{answer: "prod"},
+			asked:    "prod",
+			expected: audit.DecisionConfirmed,
+		},
+		{
+			name:     "wrong name",
+			cfg:      cfg,
+			result:   &guard.CheckResult{Context: "prod", Namespace: "payments", Scope: guard.ScopeNamespace},
+			prompter: &fakePrompter{answer: "payments"},
+			asked:    "prod",
+			expected: audit.DecisionAborted,
+		},
+		{
+			name:     "namespace name",
+			cfg:      cfg,
+			result:   &guard.CheckResult{Context: "staging", Namespace: "critical", Scope: guard.ScopeNamespace},
+			prompter: &fakePrompter{answer: "critical"},
+			asked:    "critical",
+			expected: audit.DecisionConfirmed,
+		},
+		{
+			name:     "context name for commands spanning namespaces",
+			cfg:      cfg,
+			result:   &guard.CheckResult{Context: "staging", Namespace: "critical", Scope: guard.ScopeAllNamespaces},
+			prompter: &fakePrompter{answer: "staging"},
+			asked:    "staging",
+			expected: audit.DecisionConfirmed,
+		},
+		{
+			name:     "not a terminal",
+			cfg:      cfg,
+			result:   &guard.CheckResult{Context: "prod", Namespace: "payments", Scope: guard.ScopeNamespace},
+			prompter: &fakePrompter{err: ErrNotTerminal},
+			asked:    "prod",
+			expected: audit.DecisionAborted,
+		},
+		{
+			name:     "not a terminal, failing open",
+			cfg:      failOpen,
+			result:   &guard.CheckResult{Context: "prod", Namespace: "payments", Scope: guard.ScopeNamespace},
+			prompter: &fakePrompter{err: ErrNotTerminal},
+			asked:    "prod",
+			expected: audit.DecisionUnconfirmed,
+		},
+		{
+			name:     "read error",
+			cfg:      cfg,
+			result:   &guard.CheckResult{Context: "prod", Namespace: "payments", Scope: guard.ScopeNamespace},
+			prompter: &fakePrompter{err: errors.New("input/output error")},
+			asked:    "prod",
+			expected: audit.DecisionAborted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if decision := confirmCommand(tt.prompter, tt.cfg, tt.result, &out); decision != tt.expected {
+				t.Errorf("expected %s, got %s (output %q)", tt.expected, decision, out.String())
+			}
+			if tt.prompter.asked != tt.asked {
+				t.Errorf("expected to be asked for %q, got %q", tt.asked, tt.prompter.asked)
+			}
+		})
+	}
+}
@@ -2,8 +2,13 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,12 +16,254 @@ import (
 // Config represents the guard configuration.
 type Config struct {
 	GuardedContexts []GuardedContext `yaml:"guardedContexts"`
+	// ProtectedResources are blocked for every mutating command, in guarded contexts or not.
+	ProtectedResources []ProtectedResource `yaml:"protectedResources,omitempty"`
+	// FetchManifestURLs lets the guard download -f URLs to inspect them.
+	// When disabled, objects from URLs are unknown and always guarded.
+	FetchManifestURLs bool `yaml:"fetchManifestURLs,omitempty"`
+	// ConfirmFailOpen runs commands that need confirmation after a warning when there is no terminal to ask.
+	// By default they are refused.
+	ConfirmFailOpen bool `yaml:"confirmFailOpen,omitempty"`
+	// AuditLog is the path of the audit log, ~/.kube/guard-audit.log by default.
+	AuditLog string `yaml:"auditLog,omitempty"`
+	// AuditRotation starts a new audit log file once the current one is too large or too old.
+	AuditRotation AuditRotation `yaml:"auditRotation,omitempty"`
+	// FreezeCalendars fully guard contexts during the change freezes of iCalendar files.
+	FreezeCalendars []FreezeCalendar `yaml:"freezeCalendars,omitempty"`
+	// Relaxations temporarily suspend guarded contexts, e.g. "unguard prod --for 30m".
+	Relaxations []Relaxation `yaml:"relaxations,omitempty"`
+
+	// Clock returns the current time, time.Now when nil.
+	Clock func() time.Time `yaml:"-"`
+}
+
+// AuditRotation limits the size and age of an audit log file; zero means no limit.
+type AuditRotation struct {
+	MaxSize int64         `yaml:"maxSize,omitempty"` // bytes
+	MaxAge  time.Duration `yaml:"maxAge,omitempty"`  // e.g. "168h"
 }
 
 // GuardedContext represents a protected Kubernetes context.
+// Either Name (exact context name) or Pattern (glob or anchored regex) is set.
 type GuardedContext struct {
-	Name       string   `yaml:"name"`
+	Name       string   `yaml:"name,omitempty"`
+	Pattern    string   `yaml:"pattern,omitempty"`
 	Namespaces []string `yaml:"namespaces,omitempty"` // empty means all namespaces
+	// ExcludeNamespaces are never guarded, even when matched by Namespaces.
+	ExcludeNamespaces []string `yaml:"excludeNamespaces,omitempty"`
+	// AllowCommands and DenyCommands override the default command classification.
+	// Entries are commands such as "scale", "rollout restart" or "exec";
+	// a command also covers its subcommands, and the most specific entry wins.
+	AllowCommands []string `yaml:"allowCommands,omitempty"`
+	DenyCommands  []string `yaml:"denyCommands,omitempty"`
+	// Rules decide commands on specific resource kinds and take precedence over the lists above.
+	Rules []Rule `yaml:"rules,omitempty"`
+	// BlastRadius escalates mutating commands reaching many objects, e.g. "all: deny".
+	BlastRadius map[BlastRadius]Action `yaml:"blastRadius,omitempty"`
+	// ConfirmWith is the name typed to confirm a command: "context" (default) or "namespace".
+	ConfirmWith ConfirmWith `yaml:"confirmWith,omitempty"`
+	// RequireReason demands a reason, such as a ticket ID, to override the guard.
+	// ReasonPattern is an optional regular expression the reason must match, e.g. "^(INC|CHG)-[0-9]+$".
+	RequireReason bool   `yaml:"requireReason,omitempty"`
+	ReasonPattern string `yaml:"reasonPattern,omitempty"`
+	// Schedules guard the context, or some of its namespaces, only during or outside time windows.
+	Schedules []Schedule `yaml:"schedules,omitempty"`
+}
+
+// ConfirmWith is the name typed to confirm a command.
+type ConfirmWith string
+
+const (
+	// ConfirmWithContext asks for the context name.
+	ConfirmWithContext ConfirmWith = "context"
+	// ConfirmWithNamespace asks for the namespace name, or the context name for commands
+	// spanning namespaces or acting on cluster-scoped resources.
+	ConfirmWithNamespace ConfirmWith = "namespace"
+)
+
+// BlastRadius classifies how many objects a command may reach.
+type BlastRadius string
+
+const (
+	// BlastRadiusNamed is a command acting on named objects only.
+	BlastRadiusNamed BlastRadius = "named"
+	// BlastRadiusSelector is a command acting on the objects matching a label or field selector.
+	BlastRadiusSelector BlastRadius = "selector"
+	// BlastRadiusAll is a command acting on every object of a type in a namespace,
+	// e.g. with --all, the "all" category, an unnamed type or a selector that only checks for a label's existence.
+	BlastRadiusAll BlastRadius = "all"
+	// BlastRadiusAllNamespaces is a command acting across every namespace.
+	BlastRadiusAllNamespaces BlastRadius = "all-namespaces"
+)
+
+// Action is the outcome of a rule.
+type Action string
+
+const (
+	// ActionAllow lets the command run.
+	ActionAllow Action = "allow"
+	// ActionWarn prints a warning and lets the command run.
+	ActionWarn Action = "warn"
+	// ActionConfirm asks for confirmation before the command runs.
+	ActionConfirm Action = "confirm"
+	// ActionBlock blocks the command unless the guard is overridden.
+	ActionBlock Action = "block"
+	// ActionDeny blocks the command even when the guard is overridden.
+	ActionDeny Action = "deny"
+)
+
+// actionRanks orders actions from the weakest to the strongest.
+var actionRanks = map[Action]int{
+	ActionAllow:   0,
+	ActionWarn:    1,
+	ActionConfirm: 2,
+	ActionBlock:   3,
+	ActionDeny:    4,
+}
+
+func (a Action) validate() error {
+	if _, ok := actionRanks[a]; !ok {
+		return fmt.Errorf("invalid action %q", a)
+	}
+	return nil
+}
+
+// Escalate returns the stronger of the two actions.
+func (a Action) Escalate(b Action) Action {
+	if actionRanks[b] > actionRanks[a] {
+		return b
+	}
+	return a
+}
+
+// Rule sets the action for commands acting on resource kinds.
+// The first rule matching both the command and the resource wins.
+type Rule struct {
+	// Commands such as "delete" or "rollout restart"; empty matches any command.
+	Commands []string `yaml:"commands,omitempty"`
+	// Resources as typed for kubectl, e.g. "pods", "po" or "deployments.apps"; empty matches any resource.
+	Resources []string `yaml:"resources,omitempty"`
+	Action    Action   `yaml:"action"`
+}
+
+func (r *Rule) validate() error {
+	if err := r.Action.validate(); err != nil {
+		return fmt.Errorf("rule %q: %w", r.String(), err)
+	}
+	return nil
+}
+
+// String describes the rule, e.g. "delete pods, deploy: allow".
+func (r *Rule) String() string {
+	commands := "any command"
+	if len(r.Commands) > 0 {
+		commands = strings.Join(r.Commands, ", ")
+	}
+	resources := "any resource"
+	if len(r.Resources) > 0 {
+		resources = strings.Join(r.Resources, ", ")
+	}
+	return commands + " " + resources + ": " + string(r.Action)
+}
+
+// MatchesCommand checks if the rule applies to the command path.
+func (r *Rule) MatchesCommand(path string) bool {
+	return len(r.Commands) == 0 || longestCommandMatch(r.Commands, path) >= 0
+}
+
+// Matches checks if the entry applies to the context.
+func (gc *GuardedContext) Matches(context string) bool {
+	if gc.Pattern != "" {
+		return MatchPattern(gc.Pattern, context)
+	}
+	return gc.Name == context
+}
+
+// IsNamespaceGuarded checks if the namespace is guarded by the entry.
+// Namespaces and ExcludeNamespaces accept the same patterns as context names.
+func (gc *GuardedContext) IsNamespaceGuarded(namespace string) bool {
+	for _, ns := range gc.ExcludeNamespaces {
+		if MatchPattern(ns, namespace) {
+			return false
+		}
+	}
+	// empty namespaces means all namespaces are guarded
+	if len(gc.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range gc.Namespaces {
+		if MatchPattern(ns, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// NamespaceRule describes the guarded namespaces of the entry.
+func (gc *GuardedContext) NamespaceRule() string {
+	rule := "all namespaces"
+	if len(gc.Namespaces) > 0 {
+		rule = "namespaces: " + strings.Join(gc.Namespaces, ", ")
+	}
+	if len(gc.ExcludeNamespaces) > 0 {
+		rule += " except " + strings.Join(gc.ExcludeNamespaces, ", ")
+	}
+	return rule
+}
+
+// CommandPolicy returns whether the command path is explicitly allowed or denied by the entry.
+// The bool result is false when no entry covers the command.
+func (gc *GuardedContext) CommandPolicy(path string) (deny, ok bool) {
+	allow := longestCommandMatch(gc.AllowCommands, path)
+	denied := longestCommandMatch(gc.DenyCommands, path)
+	if allow < 0 && denied < 0 {
+		return false, false
+	}
+	// deny wins a tie
+	return denied >= allow, true
+}
+
+// longestCommandMatch returns the length of the most specific entry covering path, or -1.
+func longestCommandMatch(entries []string, path string) int {
+	longest := -1
+	for _, entry := range entries {
+		if (entry == path || strings.HasPrefix(path, entry+" ")) && len(entry) > longest {
+			longest = len(entry)
+		}
+	}
+	return longest
+}
+
+// CommandRule describes the commands blocked by the entry.
+func (gc *GuardedContext) CommandRule() string {
+	rule := "mutating commands"
+	if len(gc.DenyCommands) > 0 {
+		rule += ", " + strings.Join(gc.DenyCommands, ", ")
+	}
+	if len(gc.AllowCommands) > 0 {
+		rule += " except " + strings.Join(gc.AllowCommands, ", ")
+	}
+	return rule
+}
+
+// BlastRadiusRule describes the blast radius policy, e.g. "selector: block, all: deny".
+// It is empty when the entry has no policy.
+func (gc *GuardedContext) BlastRadiusRule() string {
+	var rules []string
+	for _, radius := range []BlastRadius{BlastRadiusSelector, BlastRadiusAll, BlastRadiusAllNamespaces} {
+		if action, ok := gc.BlastRadius[radius]; ok {
+			rules = append(rules, string(radius)+": "+string(action))
+		}
+	}
+	return strings.Join(rules, ", ")
+}
+
+// String returns the name or pattern of the entry.
+func (gc *GuardedContext) String() string {
+	if gc.Pattern != "" {
+		return gc.Pattern
+	}
+	return gc.Name
 }
 
 // DefaultPath returns the default config file path.
@@ -51,9 +298,93 @@ func LoadFrom(path string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
+// Validate checks that every entry is well-formed.
+func (c *Config) Validate() error {
+	for _, gc := range c.GuardedContexts {
+		switch {
+		case gc.Name == "" && gc.Pattern == "":
+			return errors.New("guarded context requires name or pattern")
+		case gc.Name != "" && gc.Pattern != "":
+			return fmt.Errorf("guarded context %q has both name and pattern", gc.Name)
+		}
+		patterns := append([]string{}, gc.Namespaces...)
+		patterns = append(patterns, gc.ExcludeNamespaces...)
+		if gc.Pattern != "" {
+			patterns = append(patterns, gc.Pattern)
+		}
+		for _, p := range patterns {
+			if _, err := compilePattern(p); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", p, err)
+			}
+		}
+		for _, cmd := range append(append([]string{}, gc.AllowCommands...), gc.DenyCommands...) {
+			if strings.TrimSpace(cmd) == "" {
+				return fmt.Errorf("guarded context %q has an empty command entry", gc.String())
+			}
+		}
+		for _, rule := range gc.Rules {
+			if err := rule.validate(); err != nil {
+				return fmt.Errorf("guarded context %q: %w", gc.String(), err)
+			}
+		}
+		if gc.ReasonPattern != "" {
+			if !gc.RequireReason {
+				return fmt.Errorf("guarded context %q: reasonPattern requires requireReason", gc.String())
+			}
+			if _, err := regexp.Compile(gc.ReasonPattern); err != nil {
+				return fmt.Errorf("guarded context %q: invalid reasonPattern: %w", gc.String(), err)
+			}
+		}
+		switch gc.ConfirmWith {
+		case "", ConfirmWithContext, ConfirmWithNamespace:
+		default:
+			return fmt.Errorf("guarded context %q: invalid confirmWith %q", gc.String(), gc.ConfirmWith)
+		}
+		for i := range gc.Schedules {
+			if err := gc.Schedules[i].validate(); err != nil {
+				return fmt.Errorf("guarded context %q: schedule: %w", gc.String(), err)
+			}
+		}
+		for radius, action := range gc.BlastRadius {
+			switch radius {
+			case BlastRadiusSelector, BlastRadiusAll, BlastRadiusAllNamespaces:
+			case BlastRadiusNamed:
+				return fmt.Errorf("guarded context %q: blast radius %q is decided by rules", gc.String(), radius)
+			default:
+				return fmt.Errorf("guarded context %q: invalid blast radius %q", gc.String(), radius)
+			}
+			if err := action.validate(); err != nil {
+				return fmt.Errorf("guarded context %q: blast radius %q: %w", gc.String(), radius, err)
+			}
+		}
+	}
+	if c.AuditRotation.MaxSize < 0 || c.AuditRotation.MaxAge < 0 {
+		return errors.New("audit rotation limits must not be negative")
+	}
+	for _, p := range c.ProtectedResources {
+		if err := p.validate(); err != nil {
+			return err
+		}
+	}
+	for i := range c.FreezeCalendars {
+		if err := c.FreezeCalendars[i].validate(); err != nil {
+			return err
+		}
+	}
+	for _, r := range c.Relaxations {
+		if r.Context == "" {
+			return errors.New("relaxation requires context")
+		}
+	}
+	return nil
+}
+
 // Save saves the config to the default path.
 func (c *Config) Save() error {
 	path, err := DefaultPath()
@@ -77,39 +408,50 @@ func (c *Config) SaveTo(path string) error {
 	return os.WriteFile(path, data, 0o644)
 }
 
-// IsGuarded checks if the context is guarded.
-func (c *Config) IsGuarded(context string) bool {
-	for _, gc := range c.GuardedContexts {
-		if gc.Name == context {
-			return true
+// Lookup returns the entry that applies to the context, or nil if the context is not guarded
+// or its guard is relaxed.
+func (c *Config) Lookup(context string) *GuardedContext {
+	if c.Relaxation(context) != nil {
+		return nil
+	}
+	return c.Entry(context)
+}
+
+// Entry returns the entry that applies to the context, or nil, whether it is relaxed or not.
+// An exact name entry takes precedence over patterns; among patterns the first match wins.
+func (c *Config) Entry(context string) *GuardedContext {
+	for i := range c.GuardedContexts {
+		if c.GuardedContexts[i].Pattern == "" && c.GuardedContexts[i].Name == context {
+			return &c.GuardedContexts[i]
 		}
 	}
-	return false
+	for i := range c.GuardedContexts {
+		if c.GuardedContexts[i].Pattern != "" && c.GuardedContexts[i].Matches(context) {
+			return &c.GuardedContexts[i]
+		}
+	}
+	return nil
+}
+
+// IsGuarded checks if the context is guarded.
+func (c *Config) IsGuarded(context string) bool {
+	return c.Lookup(context) != nil
 }
 
 // IsNamespaceGuarded checks if the namespace in the context is guarded.
 func (c *Config) IsNamespaceGuarded(context, namespace string) bool {
-	for _, gc := range c.GuardedContexts {
-		if gc.Name == context {
-			// empty namespaces means all namespaces are guarded
-			if len(gc.Namespaces) == 0 {
-				return true
-			}
-			for _, ns := range gc.Namespaces {
-				if ns == namespace {
-					return true
-				}
-			}
-		}
+	gc := c.Lookup(context)
+	if gc == nil {
+		return false
 	}
-	return false
+	return gc.IsNamespaceGuarded(namespace)
 }
 
 // AddContext adds a context to the guarded list.
 func (c *Config) AddContext(context string, namespaces []string) {
 	// Check if already exists
 	for i, gc := range c.GuardedContexts {
-		if gc.Name == context {
+		if gc.Pattern == "" && gc.Name == context {
 			c.GuardedContexts[i].Namespaces = namespaces
 			return
 		}
@@ -120,11 +462,46 @@ func (c *Config) AddContext(context string, namespaces []string) {
 	})
 }
 
-// RemoveContext removes a context from the guarded list.
+// AddPattern adds a context pattern to the guarded list.
+func (c *Config) AddPattern(pattern string, namespaces []string) error {
+	if _, err := compilePattern(pattern); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	for i, gc := range c.GuardedContexts {
+		if gc.Pattern == pattern {
+			c.GuardedContexts[i].Namespaces = namespaces
+			return nil
+		}
+	}
+	c.GuardedContexts = append(c.GuardedContexts, GuardedContext{
+		Pattern:    pattern,
+		Namespaces: namespaces,
+	})
+	return nil
+}
+
+// SetExcludeNamespaces sets the excluded namespaces of a context or pattern entry.
+func (c *Config) SetExcludeNamespaces(context string, excludes []string) error {
+	for _, p := range excludes {
+		if _, err := compilePattern(p); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+	for i, gc := range c.GuardedContexts {
+		if gc.String() == context {
+			c.GuardedContexts[i].ExcludeNamespaces = excludes
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not guarded", context)
+}
+
+// RemoveContext removes a context or pattern from the guarded list, along with its relaxation.
 func (c *Config) RemoveContext(context string) bool {
 	for i, gc := range c.GuardedContexts {
-		if gc.Name == context {
+		if gc.String() == context {
 			c.GuardedContexts = append(c.GuardedContexts[:i], c.GuardedContexts[i+1:]...)
+			c.Unrelax(context)
 			return true
 		}
 	}
@@ -68,6 +68,191 @@ func TestConfig_IsGuarded(t *testing.T) {
 	}
 }
 
+func TestConfig_IsGuarded_Pattern(t *testing.T) {
+	cfg := &Config{
+		GuardedContexts: []GuardedContext{
+			{Pattern: "gke_acme-prod_*", Namespaces: []string{"payments"}},
+			{Pattern: "^arn:aws:eks:.*:cluster/prod-.*$"},
+		},
+	}
+
+	if !cfg.IsGuarded("gke_acme-prod_europe-west1_main") {
+		t.Error("expected glob pattern to guard context")
+	}
+	if !cfg.IsGuarded("arn:aws:eks:eu-west-1:123456789012:cluster/prod-payments") {
+		t.Error("expected regex pattern to guard context")
+	}
+	if cfg.IsGuarded("gke_acme-dev_europe-west1_main") {
+		t.Error("expected non-matching context to not be guarded")
+	}
+}
+
+func TestConfig_Lookup_Precedence(t *testing.T) {
+	cfg := &Config{
+		GuardedContexts: []GuardedContext{
+			{Pattern: "prod-*"},
+			{Pattern: "prod-eu*", Namespaces: []string{"critical"}},
+			{Name: "prod-eu", Namespaces: []string{"payments"}},
+		},
+	}
+
+	// Exact entry wins over patterns regardless of order
+	if gc := cfg.Lookup("prod-eu"); gc == nil || gc.Name != "prod-eu" {
+		t.Errorf("expected exact entry, got %+v", gc)
+	}
+	if !cfg.IsNamespaceGuarded("prod-eu", "payments") || cfg.IsNamespaceGuarded("prod-eu", "default") {
+		t.Error("expected exact entry namespaces to apply")
+	}
+
+	// First matching pattern wins
+	if gc := cfg.Lookup("prod-eu-2"); gc == nil || gc.Pattern != "prod-*" {
+		t.Errorf("expected first pattern, got %+v", gc)
+	}
+	if gc := cfg.Lookup("dev"); gc != nil {
+		t.Errorf("expected no entry, got %+v", gc)
+	}
+}
+
+func TestConfig_AddPattern(t *testing.T) {
+	cfg := &Config{}
+
+	if err := cfg.AddPattern("prod-*", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.AddPattern("prod-*", []string{"payments"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.GuardedContexts) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(cfg.GuardedContexts))
+	}
+	if len(cfg.GuardedContexts[0].Namespaces) != 1 {
+		t.Errorf("expected pattern namespaces to be updated")
+	}
+	if err := cfg.AddPattern("^prod-(", nil); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+
+	if !cfg.RemoveContext("prod-*") {
+		t.Error("expected pattern to be removed")
+	}
+}
+
+func TestLoadFrom_InvalidPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "invalid regex",
+			content: "guardedContexts:\n  - pattern: \"^prod-(\"\n",
+		},
+		{
+			name:    "name and pattern",
+			content: "guardedContexts:\n  - name: prod\n    pattern: prod-*\n",
+		},
+		{
+			name:    "invalid rule action",
+			content: "guardedContexts:\n  - name: prod\n    rules:\n      - commands: [delete]\n        action: maybe\n",
+		},
+		{
+			name:    "neither name nor pattern",
+			content: "guardedContexts:\n  - namespaces: [default]\n",
+		},
+		{
+			name:    "invalid blast radius",
+			content: "guardedContexts:\n  - name: prod\n    blastRadius:\n      everything: deny\n",
+		},
+		{
+			name:    "named blast radius",
+			content: "guardedContexts:\n  - name: prod\n    blastRadius:\n      named: block\n",
+		},
+		{
+			name:    "invalid blast radius action",
+			content: "guardedContexts:\n  - name: prod\n    blastRadius:\n      all: maybe\n",
+		},
+		{
+			name:    "invalid confirmWith",
+			content: "guardedContexts:\n  - name: prod\n    confirmWith: cluster\n",
+		},
+		{
+			name:    "negative audit rotation",
+			content: "auditRotation:\n  maxAge: -1h\n",
+		},
+		{
+			name:    "reasonPattern without requireReason",
+			content: "guardedContexts:\n  - name: prod\n    reasonPattern: ^INC-[0-9]+$\n",
+		},
+		{
+			name:    "invalid reasonPattern",
+			content: "guardedContexts:\n  - name: prod\n    requireReason: true\n    reasonPattern: ^INC-[0-9+$\n",
+		},
+		{
+			name:    "schedule without windows",
+			content: "guardedContexts:\n  - name: prod\n    schedules:\n      - guard: during\n",
+		},
+		{
+			name:    "invalid schedule guard",
+			content: "guardedContexts:\n  - name: prod\n    schedules:\n      - guard: sometimes\n        windows:\n          - from: 2026-12-20\n",
+		},
+		{
+			name:    "invalid schedule timezone",
+			content: "guardedContexts:\n  - name: prod\n    schedules:\n      - timezone: Mars/Olympus\n        windows:\n          - from: 2026-12-20\n",
+		},
+		{
+			name:    "invalid cron",
+			content: "guardedContexts:\n  - name: prod\n    schedules:\n      - windows:\n          - cron: 0 18 * *\n            duration: 1h\n",
+		},
+		{
+			name:    "cron without duration",
+			content: "guardedContexts:\n  - name: prod\n    schedules:\n      - windows:\n          - cron: 0 18 * * fri\n",
+		},
+		{
+			name:    "window with cron and dates",
+			content: "guardedContexts:\n  - name: prod\n    schedules:\n      - windows:\n          - cron: 0 18 * * fri\n            duration: 1h\n            from: 2026-12-20\n",
+		},
+		{
+			name:    "window ending before it starts",
+			content: "guardedContexts:\n  - name: prod\n    schedules:\n      - windows:\n          - from: 2026-12-20\n            until: 2026-12-01\n",
+		},
+		{
+			name:    "invalid window time",
+			content: "guardedContexts:\n  - name: prod\n    schedules:\n      - windows:\n          - from: next week\n",
+		},
+		{
+			name:    "freeze calendar without file",
+			content: "freezeCalendars:\n  - contexts: [prod]\n",
+		},
+		{
+			name:    "freeze calendar without contexts",
+			content: "freezeCalendars:\n  - file: release.ics\n",
+		},
+		{
+			name:    "freeze calendar with invalid summary",
+			content: "freezeCalendars:\n  - file: release.ics\n    contexts: [prod]\n    summary: ^Release (\n",
+		},
+		{
+			name:    "protected resource without name",
+			content: "protectedResources:\n  - kind: secret\n",
+		},
+		{
+			name:    "protected resource with invalid pattern",
+			content: "protectedResources:\n  - kind: secret\n    name: ^db-(\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "guard.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+			if _, err := LoadFrom(path); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
 func TestConfig_IsNamespaceGuarded(t *testing.T) {
 	cfg := &Config{
 		GuardedContexts: []GuardedContext{
@@ -98,6 +283,227 @@ func TestConfig_IsNamespaceGuarded(t *testing.T) {
 	}
 }
 
+func TestConfig_IsNamespaceGuarded_Patterns(t *testing.T) {
+	cfg := &Config{
+		GuardedContexts: []GuardedContext{
+			{Name: "prod", ExcludeNamespaces: []string{"dev-*", "sandbox"}},
+			{Name: "shared", Namespaces: []string{"team-*-prod"}, ExcludeNamespaces: []string{"team-legacy-prod"}},
+		},
+	}
+
+	tests := []struct {
+		context   string
+		namespace string
+		expected  bool
+	}{
+		{context: "prod", namespace: "payments", expected: true},
+		{context: "prod", namespace: "dev-alice", expected: false},
+		{context: "prod", namespace: "sandbox", expected: false},
+		{context: "prod", namespace: "sandbox-2", expected: true},
+		{context: "shared", namespace: "team-a-prod", expected: true},
+		{context: "shared", namespace: "team-a-staging", expected: false},
+		{context: "shared", namespace: "team-legacy-prod", expected: false},
+	}
+
+	for _, tt := range tests {
+		if result := cfg.IsNamespaceGuarded(tt.context, tt.namespace); result != tt.expected {
+			t.Errorf("%s/%s: expected %v, got %v", tt.context, tt.namespace, tt.expected, result)
+		}
+	}
+}
+
+func TestGuardedContext_NamespaceRule(t *testing.T) {
+	tests := []struct {
+		gc       GuardedContext
+		expected string
+	}{
+		{gc: GuardedContext{Name: "prod"}, expected: "all namespaces"},
+		{gc: GuardedContext{Name: "prod", Namespaces: []string{"a", "b-*"}}, expected: "namespaces: a, b-*"},
+		{gc: GuardedContext{Name: "prod", ExcludeNamespaces: []string{"dev-*", "sandbox"}}, expected: "all namespaces except dev-*, sandbox"},
+		{gc: GuardedContext{Name: "prod", Namespaces: []string{"team-*"}, ExcludeNamespaces: []string{"team-x"}}, expected: "namespaces: team-* except team-x"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.gc.NamespaceRule(); result != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, result)
+		}
+	}
+}
+
+func TestConfig_SetExcludeNamespaces(t *testing.T) {
+	cfg := &Config{}
+	cfg.AddContext("prod", nil)
+
+	if err := cfg.SetExcludeNamespaces("prod", []string{"sandbox"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.IsNamespaceGuarded("prod", "sandbox") {
+		t.Error("expected 'prod/sandbox' to not be guarded")
+	}
+	if err := cfg.SetExcludeNamespaces("dev", []string{"sandbox"}); err == nil {
+		t.Error("expected error for unguarded context")
+	}
+	if err := cfg.SetExcludeNamespaces("prod", []string{"^dev-("}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestGuardedContext_CommandPolicy(t *testing.T) {
+	gc := GuardedContext{
+		Name:          "prod",
+		AllowCommands: []string{"scale", "rollout restart", "set image"},
+		DenyCommands:  []string{"exec", "port-forward", "rollout", "set"},
+	}
+
+	tests := []struct {
+		path string
+		deny bool
+		ok   bool
+	}{
+		{path: "scale", deny: false, ok: true},
+		{path: "rollout restart", deny: false, ok: true},
+		{path: "rollout undo", deny: true, ok: true},
+		{path: "set image", deny: false, ok: true},
+		{path: "set env", deny: true, ok: true},
+		{path: "exec", deny: true, ok: true},
+		{path: "port-forward", deny: true, ok: true},
+		{path: "delete", ok: false},
+		{path: "scaled", ok: false},
+	}
+
+	for _, tt := range tests {
+		deny, ok := gc.CommandPolicy(tt.path)
+		if deny != tt.deny || ok != tt.ok {
+			t.Errorf("%s: expected (%v, %v), got (%v, %v)", tt.path, tt.deny, tt.ok, deny, ok)
+		}
+	}
+
+	tie := GuardedContext{Name: "prod", AllowCommands: []string{"scale"}, DenyCommands: []string{"scale"}}
+	if deny, _ := tie.CommandPolicy("scale"); !deny {
+		t.Error("expected deny to win a tie")
+	}
+}
+
+func TestGuardedContext_CommandRule(t *testing.T) {
+	gc := GuardedContext{Name: "prod"}
+	if result := gc.CommandRule(); result != "mutating commands" {
+		t.Errorf("unexpected rule: %q", result)
+	}
+
+	gc = GuardedContext{Name: "prod", AllowCommands: []string{"scale"}, DenyCommands: []string{"exec", "port-forward"}}
+	if result := gc.CommandRule(); result != "mutating commands, exec, port-forward except scale" {
+		t.Errorf("unexpected rule: %q", result)
+	}
+}
+
+func TestRule_MatchesCommand(t *testing.T) {
+	rule := Rule{Commands: []string{"delete", "rollout restart"}}
+	for _, path := range []string{"delete", "rollout restart"} {
+		if !rule.MatchesCommand(path) {
+			t.Errorf("expected rule to match %q", path)
+		}
+	}
+	for _, path := range []string{"rollout undo", "get"} {
+		if rule.MatchesCommand(path) {
+			t.Errorf("expected rule to not match %q", path)
+		}
+	}
+
+	catchAll := Rule{}
+	if !catchAll.MatchesCommand("get") {
+		t.Error("expected rule without commands to match any command")
+	}
+}
+
+func TestRule_String(t *testing.T) {
+	rule := Rule{Commands: []string{"delete"}, Resources: []string{"pods", "deploy"}, Action: ActionAllow}
+	if result := rule.String(); result != "delete pods, deploy: allow" {
+		t.Errorf("unexpected rule: %q", result)
+	}
+
+	rule = Rule{Resources: []string{"secrets"}, Action: ActionBlock}
+	if result := rule.String(); result != "any command secrets: block" {
+		t.Errorf("unexpected rule: %q", result)
+	}
+}
+
+func TestAction_Escalate(t *testing.T) {
+	tests := []struct {
+		a, b     Action
+		expected Action
+	}{
+		{ActionAllow, ActionBlock, ActionBlock},
+		{ActionBlock, ActionAllow, ActionBlock},
+		{ActionBlock, ActionDeny, ActionDeny},
+		{ActionDeny, ActionBlock, ActionDeny},
+		{ActionAllow, ActionAllow, ActionAllow},
+		{ActionWarn, ActionConfirm, ActionConfirm},
+		{ActionConfirm, ActionBlock, ActionBlock},
+		{ActionConfirm, ActionWarn, ActionConfirm},
+	}
+
+	for _, tt := range tests {
+		if result := tt.a.Escalate(tt.b); result != tt.expected {
+			t.Errorf("%s.Escalate(%s) = %s, expected %s", tt.a, tt.b, result, tt.expected)
+		}
+	}
+}
+
+func TestGuardedContext_BlastRadiusRule(t *testing.T) {
+	gc := GuardedContext{Name: "prod"}
+	if rule := gc.BlastRadiusRule(); rule != "" {
+		t.Errorf("expected no rule, got %q", rule)
+	}
+
+	gc.BlastRadius = map[BlastRadius]Action{
+		BlastRadiusAllNamespaces: ActionDeny,
+		BlastRadiusSelector:      ActionBlock,
+	}
+	if rule := gc.BlastRadiusRule(); rule != "selector: block, all-namespaces: deny" {
+		t.Errorf("unexpected rule: %q", rule)
+	}
+}
+
+func TestProtectedResource_Matches(t *testing.T) {
+	p := ProtectedResource{Context: "prod-*", Namespace: "payments", Kind: "secret", Name: "db-*"}
+
+	if !p.MatchesContext("prod-eu") || p.MatchesContext("staging") {
+		t.Error("unexpected context match")
+	}
+	if !p.MatchesNamespace("payments") || p.MatchesNamespace("default") || p.MatchesNamespace("") {
+		t.Error("unexpected namespace match")
+	}
+	if !p.MatchesName("db-password") || p.MatchesName("api-token") {
+		t.Error("unexpected name match")
+	}
+	if !p.MatchesName("") {
+		t.Error("expected an unnamed object to match")
+	}
+
+	anywhere := ProtectedResource{Kind: "node", Name: "control-plane"}
+	if !anywhere.MatchesContext("dev") || !anywhere.MatchesNamespace("") {
+		t.Error("expected an entry without context and namespace to match everywhere")
+	}
+}
+
+func TestProtectedResource_String(t *testing.T) {
+	tests := []struct {
+		resource ProtectedResource
+		expected string
+	}{
+		{ProtectedResource{Kind: "secret", Name: "db-*"}, "secret/db-*"},
+		{ProtectedResource{Context: "prod", Kind: "secret", Name: "db-*"}, "secret/db-* in prod"},
+		{ProtectedResource{Namespace: "payments", Kind: "secret", Name: "db-*"}, "secret/db-* in namespace payments"},
+		{ProtectedResource{Context: "prod", Namespace: "payments", Kind: "secret", Name: "db-*"}, "secret/db-* in prod/payments"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.resource.String(); result != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, result)
+		}
+	}
+}
+
 func TestConfig_SaveAndLoad(t *testing.T) {
 	tmpDir := t.TempDir()
 	path := filepath.Join(tmpDir, "guard.yaml")
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSpec is a parsed five-field cron expression: minute, hour, day of month, month and day of week.
+// Each field is a bitset of the values it matches.
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record a "*" day field; as in cron, when both day fields are
+	// restricted a time matches either of them.
+	domAny, dowAny bool
+}
+
+type cronField struct {
+	name        string
+	first, last int
+	names       []string // names of the values from first, e.g. "jan" or "sun"
+}
+
+var cronFields = [5]cronField{
+	{name: "minute", first: 0, last: 59},
+	{name: "hour", first: 0, last: 23},
+	{name: "day of month", first: 1, last: 31},
+	{name: "month", first: 1, last: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	// 7 is Sunday as well
+	{name: "day of week", first: 0, last: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// parseCron parses a cron expression such as "0 18 * * fri" or "*/15 9-17 * * mon-fri".
+// Fields accept "*", values, names of months and days, ranges, lists and steps.
+func parseCron(expr string) (*cronSpec, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron %q: expected %d fields, got %d", expr, len(cronFields), len(fields))
+	}
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := cronFields[i].parse(field)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expr, err)
+		}
+		bits[i] = b
+	}
+	// fold Sunday as 7 into 0
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+	return &cronSpec{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+func (f *cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if before, after, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(after)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", after, f.name)
+			}
+			rng, step = before, n
+		}
+		lo, hi := f.first, f.last
+		switch before, after, ok := strings.Cut(rng, "-"); {
+		case rng == "*":
+		case ok:
+			var err error
+			if lo, err = f.value(before); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(after); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s", rng, f.name)
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if step == 1 {
+				// a single value, while "5/10" runs from 5 to the maximum
+				hi = v
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (f *cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.first + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.first || v > f.last {
+		return 0, fmt.Errorf("invalid %s %q", f.name, s)
+	}
+	return v, nil
+}
+
+func (c *cronSpec) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// lastStart returns the latest matching minute at or before t and after t minus d.
+// Times are taken in t's location.
+func (c *cronSpec) lastStart(t time.Time, d time.Duration) (time.Time, bool) {
+	earliest := t.Add(-d)
+	s := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+	for s.After(earliest) {
+		// skip whole months, days and hours that cannot match
+		switch {
+		case c.month&(1<<int(s.Month())) == 0:
+			s = time.Date(s.Year(), s.Month(), 1, 0, 0, 0, 0, s.Location()).Add(-time.Minute)
+		case !c.dayMatches(s):
+			s = time.Date(s.Year(), s.Month(), s.Day(), 0, 0, 0, 0, s.Location()).Add(-time.Minute)
+		case c.hour&(1<<s.Hour()) == 0:
+			s = time.Date(s.Year(), s.Month(), s.Day(), s.Hour(), 0, 0, 0, s.Location()).Add(-time.Minute)
+		case c.minute&(1<<s.Minute()) == 0:
+			s = s.Add(-time.Minute)
+		default:
+			return s, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"0 18 * *",
+		"0 18 * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"* * * * fri-mon",
+		"*/0 * * * *",
+		"* * * * someday",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := parseCron(expr); err == nil {
+				t.Errorf("expected error for %q", expr)
+			}
+		})
+	}
+}
+
+func TestCronSpec_LastStart(t *testing.T) {
+	// 2026-01-02 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 1, day, hour, minute, 30, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		now      time.Time
+		duration time.Duration
+		start    time.Time
+		ok       bool
+	}{
+		{
+			name:     "inside a weekend window",
+			expr:     "0 18 * * fri",
+			now:      at(4, 12, 0),
+			duration: 62 * time.Hour,
+			start:    time.Date(2026, 1, 2, 18, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "before the window starts",
+			expr:     "0 18 * * fri",
+			now:      at(2, 17, 59),
+			duration: 62 * time.Hour,
+		},
+		{
+			name:     "after the window ends",
+			expr:     "0 18 * * fri",
+			now:      at(5, 8, 0),
+			duration: 62 * time.Hour,
+		},
+		{
+			name:     "at the start",
+			expr:     "0 18 * * 5",
+			now:      at(2, 18, 0),
+			duration: time.Hour,
+			start:    time.Date(2026, 1, 2, 18, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "sunday as 7",
+			expr:     "0 0 * * 7",
+			now:      at(4, 10, 0),
+			duration: 24 * time.Hour,
+			start:    time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "ranges and steps",
+			expr:     "*/15 9-17 * * mon-fri",
+			now:      at(2, 9, 20),
+			duration: 10 * time.Minute,
+			start:    time.Date(2026, 1, 2, 9, 15, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "day of month or day of week",
+			expr:     "0 0 1 * sun",
+			now:      at(4, 10, 0),
+			duration: 24 * time.Hour,
+			start:    time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "month names",
+			expr:     "0 0 20 dec *",
+			now:      at(2, 10, 0),
+			duration: 15 * 24 * time.Hour,
+			start:    time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := parseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			start, ok := spec.lastStart(tt.now, tt.duration)
+			if ok != tt.ok || !start.Equal(tt.start) {
+				t.Errorf("expected %s (%v), got %s (%v)", tt.start, tt.ok, start, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sivchari/kubectl-guard/internal/ical"
+)
+
+// FreezeCalendar marks change freezes from the events of a local iCalendar (.ics) file.
+// During a freeze the listed contexts are fully guarded, whatever their entries say.
+type FreezeCalendar struct {
+	// File is the path of the .ics file, read on every check.
+	File string `yaml:"file"`
+	// Contexts are the context names or patterns frozen by the events.
+	Contexts []string `yaml:"contexts"`
+	// Summary is a pattern the event summary must match, e.g. "*freeze*"; empty matches any event.
+	Summary string `yaml:"summary,omitempty"`
+	// Categories are the event categories that mark a freeze; empty matches any event.
+	Categories []string `yaml:"categories,omitempty"`
+}
+
+// Freeze is a change freeze from a calendar event.
+type Freeze struct {
+	Summary    string
+	Start, End time.Time
+}
+
+// String describes the freeze, e.g. "Release 2.0 (2026-12-20 09:00 to 2026-12-22 18:00)".
+func (f *Freeze) String() string {
+	const layout = "2006-01-02 15:04"
+	return f.Summary + " (" + f.Start.Local().Format(layout) + " to " + f.End.Local().Format(layout) + ")"
+}
+
+func (fc *FreezeCalendar) validate() error {
+	switch {
+	case fc.File == "":
+		return errors.New("freeze calendar requires file")
+	case len(fc.Contexts) == 0:
+		return fmt.Errorf("freeze calendar %q requires contexts", fc.File)
+	}
+	patterns := append([]string{}, fc.Contexts...)
+	if fc.Summary != "" {
+		patterns = append(patterns, fc.Summary)
+	}
+	for _, p := range patterns {
+		if _, err := compilePattern(p); err != nil {
+			return fmt.Errorf("freeze calendar %q: invalid pattern %q: %w", fc.File, p, err)
+		}
+	}
+	return nil
+}
+
+// MatchesContext checks if the calendar freezes the context.
+func (fc *FreezeCalendar) MatchesContext(context string) bool {
+	for _, p := range fc.Contexts {
+		if MatchPattern(p, context) {
+			return true
+		}
+	}
+	return false
+}
+
+// freezeHorizon is how far ahead of the time recurring freezes are expanded.
+const freezeHorizon = 366 * 24 * time.Hour
+
+// Freezes reads the freezes of the calendar in effect at the time or starting within a year,
+// expanding recurring events and skipping cancelled events and those not matching the filters.
+// Events that cannot be read are skipped as well, unless they match the filters: a freeze must not be missed.
+func (fc *FreezeCalendar) Freezes(now time.Time) ([]Freeze, error) {
+	events, err := ical.ParseFile(fc.File, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read freeze calendar: %w", err)
+	}
+	for i := range events {
+		e := &events[i]
+		if e.Err != nil && !e.Cancelled() && fc.matches(e) {
+			return nil, fmt.Errorf("failed to read freeze calendar %s: event %q: %w", fc.File, e.Summary, e.Err)
+		}
+	}
+
+	var freezes []Freeze
+	for _, e := range ical.Expand(events, now, now.Add(freezeHorizon)) {
+		if e.Cancelled() || !e.End.After(e.Start) || !fc.matches(&e) {
+			continue
+		}
+		freezes = append(freezes, Freeze{Summary: e.Summary, Start: e.Start, End: e.End})
+	}
+	return freezes, nil
+}
+
+func (fc *FreezeCalendar) matches(e *ical.Event) bool {
+	if fc.Summary != "" && !MatchPattern(fc.Summary, e.Summary) {
+		return false
+	}
+	if len(fc.Categories) == 0 {
+		return true
+	}
+	for _, c := range fc.Categories {
+		if e.HasCategory(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveFreeze returns the freeze the context is in at the time, or nil.
+// When freezes overlap, the one ending last is returned.
+func (c *Config) ActiveFreeze(context string, now time.Time) (*Freeze, error) {
+	var active *Freeze
+	for i := range c.FreezeCalendars {
+		fc := &c.FreezeCalendars[i]
+		if !fc.MatchesContext(context) {
+			continue
+		}
+		freezes, err := fc.Freezes(now)
+		if err != nil {
+			return nil, err
+		}
+		for j := range freezes {
+			f := &freezes[j]
+			if !now.Before(f.Start) && now.Before(f.End) && (active == nil || f.End.After(active.End)) {
+				active = f
+			}
+		}
+	}
+	return active, nil
+}
+
+// CurrentFreeze returns the freeze in effect at the time, or else the next one to start.
+// It returns nil when there is neither.
+func CurrentFreeze(freezes []Freeze, now time.Time) *Freeze {
+	var next *Freeze
+	for i := range freezes {
+		f := &freezes[i]
+		switch {
+		case !now.Before(f.Start) && now.Before(f.End):
+			return f
+		case f.Start.After(now) && (next == nil || f.Start.Before(next.Start)):
+			next = f
+		}
+	}
+	return next
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const freezeCalendar = `BEGIN:VCALENDAR
+BEGIN:VEVENT
+SUMMARY:Release 2.0 freeze
+CATEGORIES:FREEZE
+DTSTART:20261220T090000Z
+DTEND:20261222T180000Z
+END:VEVENT
+BEGIN:VEVENT
+SUMMARY:Team offsite
+DTSTART:20261221T090000Z
+DTEND:20261221T170000Z
+END:VEVENT
+BEGIN:VEVENT
+SUMMARY:Year-end freeze
+CATEGORIES:Freeze
+DTSTART:20261224T000000Z
+DTEND:20270102T000000Z
+END:VEVENT
+BEGIN:VEVENT
+SUMMARY:Cancelled freeze
+CATEGORIES:FREEZE
+STATUS:CANCELLED
+DTSTART:20261201T000000Z
+DTEND:20261231T000000Z
+END:VEVENT
+END:VCALENDAR
+`
+
+func writeFreezeCalendar(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "release.ics")
+	if err := os.WriteFile(path, []byte(freezeCalendar), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	return path
+}
+
+func TestFreezeCalendar_Freezes(t *testing.T) {
+	path := writeFreezeCalendar(t)
+
+	tests := []struct {
+		name     string
+		calendar FreezeCalendar
+		expected []string
+	}{
+		{
+			name:     "every event",
+			calendar: FreezeCalendar{File: path, Contexts: []string{"prod"}},
+			expected: []string{"Release 2.0 freeze", "Team offsite", "Year-end freeze"},
+		},
+		{
+			name:     "by category",
+			calendar: FreezeCalendar{File: path, Contexts: []string{"prod"}, Categories: []string{"freeze"}},
+			expected: []string{"Release 2.0 freeze", "Year-end freeze"},
+		},
+		{
+			name:     "by summary",
+			calendar: FreezeCalendar{File: path, Contexts: []string{"prod"}, Summary: "Release *"},
+			expected: []string{"Release 2.0 freeze"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			freezes, err := tt.calendar.Freezes(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(freezes) != len(tt.expected) {
+				t.Fatalf("expected %d freezes, got %+v", len(tt.expected), freezes)
+			}
+			for i, f := range freezes {
+				if f.Summary != tt.expected[i] {
+					t.Errorf("at index %d: expected %q, got %q", i, tt.expected[i], f.Summary)
+				}
+			}
+		})
+	}
+
+	if _, err := (&FreezeCalendar{File: filepath.Join(t.TempDir(), "missing.ics")}).Freezes(time.Now()); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestFreezeCalendar_Freezes_Recurring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weekly.ics")
+	calendar := "BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:weekend@example.com\n" +
+		"SUMMARY:Weekend freeze\n" +
+		"DTSTART:20260102T180000Z\n" +
+		"DTEND:20260105T080000Z\n" +
+		"RRULE:FREQ=WEEKLY\n" +
+		"EXDATE:20260109T180000Z\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n"
+	if err := os.WriteFile(path, []byte(calendar), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	cfg := &Config{FreezeCalendars: []FreezeCalendar{{File: path, Contexts: []string{"prod"}}}}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected bool
+	}{
+		{name: "first occurrence", now: time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC), expected: true},
+		{name: "later occurrence", now: time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC), expected: true},
+		{name: "excluded occurrence", now: time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC), expected: false},
+		{name: "weekday", now: time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC), expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := cfg.ActiveFreeze("prod", tt.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (f != nil) != tt.expected {
+				t.Errorf("expected frozen=%v, got %+v", tt.expected, f)
+			}
+		})
+	}
+}
+
+func TestFreezeCalendar_Freezes_InvalidEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mixed.ics")
+	calendar := "BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\n" +
+		"SUMMARY:Board meeting\n" +
+		"DTSTART:20261201T090000Z\n" +
+		"RRULE:FREQ=MONTHLY;BYDAY=MO;BYSETPOS=-1\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"SUMMARY:Release freeze\n" +
+		"CATEGORIES:FREEZE\n" +
+		"DTSTART:20261220T090000Z\n" +
+		"DTEND:20261222T180000Z\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n"
+	if err := os.WriteFile(path, []byte(calendar), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	now := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	// the unreadable event is not a freeze
+	freezes, err := (&FreezeCalendar{File: path, Contexts: []string{"prod"}, Categories: []string{"FREEZE"}}).Freezes(now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(freezes) != 1 || freezes[0].Summary != "Release freeze" {
+		t.Errorf("expected the release freeze, got %+v", freezes)
+	}
+
+	// the unreadable event may be a freeze
+	if _, err := (&FreezeCalendar{File: path, Contexts: []string{"prod"}}).Freezes(now); err == nil {
+		t.Error("expected error for an unreadable event matching the filters")
+	}
+}
+
+func TestConfig_ActiveFreeze(t *testing.T) {
+	cfg := &Config{
+		FreezeCalendars: []FreezeCalendar{
+			{File: writeFreezeCalendar(t), Contexts: []string{"prod-*"}, Categories: []string{"FREEZE"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		context  string
+		now      time.Time
+		expected string
+	}{
+		{name: "during a freeze", context: "prod-eu", now: time.Date(2026, 12, 21, 12, 0, 0, 0, time.UTC), expected: "Release 2.0 freeze"},
+		{name: "between freezes", context: "prod-eu", now: time.Date(2026, 12, 23, 12, 0, 0, 0, time.UTC)},
+		{name: "other context", context: "staging", now: time.Date(2026, 12, 21, 12, 0, 0, 0, time.UTC)},
+		{name: "at the end", context: "prod-eu", now: time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := cfg.ActiveFreeze(tt.context, tt.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			summary := ""
+			if f != nil {
+				summary = f.Summary
+			}
+			if summary != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, summary)
+			}
+		})
+	}
+}
+
+func TestCurrentFreeze(t *testing.T) {
+	freezes := []Freeze{
+		{Summary: "year-end", Start: time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC), End: time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Summary: "release", Start: time.Date(2026, 12, 20, 9, 0, 0, 0, time.UTC), End: time.Date(2026, 12, 22, 18, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		now      time.Time
+		expected string
+	}{
+		{now: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), expected: "release"},
+		{now: time.Date(2026, 12, 21, 0, 0, 0, 0, time.UTC), expected: "release"},
+		{now: time.Date(2026, 12, 23, 0, 0, 0, 0, time.UTC), expected: "year-end"},
+		{now: time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC), expected: ""},
+	}
+	for _, tt := range tests {
+		summary := ""
+		if f := CurrentFreeze(freezes, tt.now); f != nil {
+			summary = f.Summary
+		}
+		if summary != tt.expected {
+			t.Errorf("at %s: expected %q, got %q", tt.now, tt.expected, summary)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MatchPattern matches s against a pattern.
+// A pattern starting with "^" is a regular expression that must match the whole string.
+// Otherwise it is a glob where "*" matches any run of characters (including "/" and ":")
+// and "?" matches a single character.
+func MatchPattern(pattern, s string) bool {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "^") {
+		return regexp.Compile("^(?:" + pattern[1:] + ")$")
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
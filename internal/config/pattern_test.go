@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		input    string
+		expected bool
+	}{
+		{pattern: "prod-*", input: "prod-eu", expected: true},
+		{pattern: "prod-*", input: "staging-eu", expected: false},
+		{pattern: "gke_acme-prod_*", input: "gke_acme-prod_europe-west1_main", expected: true},
+		{pattern: "arn:aws:eks:*:cluster/prod-*", input: "arn:aws:eks:eu-west-1:123456789012:cluster/prod-payments", expected: true},
+		{pattern: "arn:aws:eks:*:cluster/prod-*", input: "arn:aws:eks:eu-west-1:123456789012:cluster/dev-payments", expected: false},
+		{pattern: "prod-?", input: "prod-1", expected: true},
+		{pattern: "prod-?", input: "prod-12", expected: false},
+		{pattern: "prod.eu", input: "prod-eu", expected: false},
+		{pattern: "^prod-(eu|us)$", input: "prod-us", expected: true},
+		{pattern: "^prod-(eu|us)", input: "prod-usx", expected: false},
+		{pattern: "^prod-[0-9]+", input: "prod-42", expected: true},
+		{pattern: "^prod-[", input: "prod-[", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.input, func(t *testing.T) {
+			if result := MatchPattern(tt.pattern, tt.input); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
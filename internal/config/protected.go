@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ProtectedResource is an individual resource that mutating commands must never touch,
+// regardless of whether its context is guarded.
+type ProtectedResource struct {
+	// Context is a context name or pattern; empty matches any context.
+	Context string `yaml:"context,omitempty"`
+	// Namespace is a namespace name or pattern; empty matches any namespace.
+	Namespace string `yaml:"namespace,omitempty"`
+	// Kind as typed for kubectl, e.g. "secret", "deploy" or "certificates.cert-manager.io".
+	Kind string `yaml:"kind"`
+	// Name is a resource name or pattern.
+	Name string `yaml:"name"`
+}
+
+func (p *ProtectedResource) validate() error {
+	switch {
+	case p.Kind == "":
+		return errors.New("protected resource requires kind")
+	case p.Name == "":
+		return fmt.Errorf("protected resource %q requires name", p.Kind)
+	}
+	for _, pattern := range []string{p.Context, p.Namespace, p.Name} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := compilePattern(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// MatchesContext checks if the entry applies to the context.
+func (p *ProtectedResource) MatchesContext(context string) bool {
+	return p.Context == "" || MatchPattern(p.Context, context)
+}
+
+// MatchesNamespace checks if the entry applies to the namespace.
+// An empty namespace (a cluster-scoped object) only matches entries without a namespace.
+func (p *ProtectedResource) MatchesNamespace(namespace string) bool {
+	if p.Namespace == "" {
+		return true
+	}
+	return namespace != "" && MatchPattern(p.Namespace, namespace)
+}
+
+// MatchesName checks if the entry protects the named object.
+// An empty name stands for every object of the kind, e.g. with a selector, and always matches.
+func (p *ProtectedResource) MatchesName(name string) bool {
+	return name == "" || MatchPattern(p.Name, name)
+}
+
+// String describes the entry, e.g. "secret/db-* in prod/payments".
+func (p *ProtectedResource) String() string {
+	s := p.Kind + "/" + p.Name
+	switch {
+	case p.Context != "" && p.Namespace != "":
+		s += " in " + p.Context + "/" + p.Namespace
+	case p.Context != "":
+		s += " in " + p.Context
+	case p.Namespace != "":
+		s += " in namespace " + p.Namespace
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Relaxation suspends the guard of a context until it expires.
+type Relaxation struct {
+	// Context is a context name, or the name or pattern of an entry to relax every context it guards.
+	Context string    `yaml:"context"`
+	Until   time.Time `yaml:"until"`
+}
+
+// Remaining returns how long the relaxation lasts from now, or zero once it has expired.
+func (r *Relaxation) Remaining(now time.Time) time.Duration {
+	if d := r.Until.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Now returns the current time of the config's clock.
+func (c *Config) Now() time.Time {
+	if c.Clock != nil {
+		return c.Clock()
+	}
+	return time.Now()
+}
+
+// Relax suspends the guard of a context, or of every context an entry guards, for the duration.
+// Relaxing again replaces the previous expiry; expired relaxations are dropped.
+func (c *Config) Relax(context string, d time.Duration) (time.Time, error) {
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid duration %s", d)
+	}
+	if c.entryNamed(context) == nil && c.Entry(context) == nil {
+		return time.Time{}, fmt.Errorf("%s is not guarded", context)
+	}
+	c.Unrelax(context)
+	until := c.Now().Add(d).UTC().Truncate(time.Second)
+	c.Relaxations = append(c.Relaxations, Relaxation{Context: context, Until: until})
+	return until, nil
+}
+
+// Unrelax guards a relaxed context again and reports whether it was relaxed.
+// Expired relaxations are dropped as well.
+func (c *Config) Unrelax(context string) bool {
+	now := c.Now()
+	found := false
+	kept := c.Relaxations[:0]
+	for _, r := range c.Relaxations {
+		switch {
+		case r.Context == context:
+			found = found || r.Remaining(now) > 0
+		case r.Remaining(now) > 0:
+			kept = append(kept, r)
+		}
+	}
+	c.Relaxations = kept
+	if len(c.Relaxations) == 0 {
+		c.Relaxations = nil
+	}
+	return found
+}
+
+// Relaxation returns the active relaxation suspending the guard of the context, or nil.
+// The relaxation names either the context or the entry that applies to it; the longest one wins.
+func (c *Config) Relaxation(context string) *Relaxation {
+	gc := c.Entry(context)
+	if gc == nil {
+		return nil
+	}
+	now := c.Now()
+	var found *Relaxation
+	for i := range c.Relaxations {
+		r := &c.Relaxations[i]
+		if r.Context != context && r.Context != gc.String() {
+			continue
+		}
+		if r.Remaining(now) > 0 && (found == nil || r.Until.After(found.Until)) {
+			found = r
+		}
+	}
+	return found
+}
+
+// EntryRelaxations returns the active relaxations of the entry and of the contexts it guards.
+func (c *Config) EntryRelaxations(gc *GuardedContext) []Relaxation {
+	now := c.Now()
+	var result []Relaxation
+	for _, r := range c.Relaxations {
+		if r.Remaining(now) > 0 && (r.Context == gc.String() || c.Entry(r.Context) == gc) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// entryNamed returns the entry whose name or pattern is the string, or nil.
+func (c *Config) entryNamed(s string) *GuardedContext {
+	for i := range c.GuardedContexts {
+		if c.GuardedContexts[i].String() == s {
+			return &c.GuardedContexts[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfig_Relax(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	cfg := &Config{
+		GuardedContexts: []GuardedContext{
+			{Name: "prod"},
+			{Pattern: "staging-*"},
+		},
+		Clock: func() time.Time { return now },
+	}
+
+	until, err := cfg.Relax("prod", 30*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := now.Add(30 * time.Minute); !until.Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, until)
+	}
+	if cfg.IsGuarded("prod") {
+		t.Error("expected prod to be relaxed")
+	}
+	if cfg.Entry("prod") == nil {
+		t.Error("expected the entry of a relaxed context")
+	}
+
+	now = now.Add(30 * time.Minute)
+	if !cfg.IsGuarded("prod") {
+		t.Error("expected prod to be guarded again once the relaxation expired")
+	}
+
+	// Relaxing a pattern entry relaxes every context it guards
+	if _, err := cfg.Relax("staging-*", time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.IsGuarded("staging-eu") || cfg.IsGuarded("staging-us") {
+		t.Error("expected staging contexts to be relaxed")
+	}
+	// A single context guarded by a pattern can be relaxed too
+	if _, err := cfg.Relax("staging-eu", 2*time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r := cfg.Relaxation("staging-eu"); r == nil || r.Remaining(now) != 2*time.Hour {
+		t.Errorf("expected the longest relaxation, got %+v", r)
+	}
+	if len(cfg.Relaxations) != 2 {
+		t.Errorf("expected the expired relaxation to be dropped, got %+v", cfg.Relaxations)
+	}
+	if rs := cfg.EntryRelaxations(&cfg.GuardedContexts[1]); len(rs) != 2 {
+		t.Errorf("expected 2 relaxations of the pattern entry, got %+v", rs)
+	}
+
+	if _, err := cfg.Relax("dev", time.Hour); err == nil {
+		t.Error("expected error for an unguarded context")
+	}
+	if _, err := cfg.Relax("prod", 0); err == nil {
+		t.Error("expected error for a zero duration")
+	}
+}
+
+func TestConfig_Unrelax(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	cfg := &Config{
+		GuardedContexts: []GuardedContext{{Name: "prod"}, {Name: "staging"}},
+		Relaxations: []Relaxation{
+			{Context: "prod", Until: now.Add(time.Hour)},
+			{Context: "staging", Until: now.Add(-time.Hour)},
+		},
+		Clock: func() time.Time { return now },
+	}
+
+	if cfg.Unrelax("staging") {
+		t.Error("expected an expired relaxation not to count")
+	}
+	if !cfg.Unrelax("prod") {
+		t.Error("expected prod to be relaxed")
+	}
+	if !cfg.IsGuarded("prod") {
+		t.Error("expected prod to be guarded again")
+	}
+	if cfg.Relaxations != nil {
+		t.Errorf("expected no relaxations, got %+v", cfg.Relaxations)
+	}
+}
+
+func TestConfig_RemoveContext_Relaxed(t *testing.T) {
+	cfg := &Config{GuardedContexts: []GuardedContext{{Name: "prod"}}}
+	if _, err := cfg.Relax("prod", time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.RemoveContext("prod")
+	if len(cfg.Relaxations) != 0 {
+		t.Errorf("expected the relaxation to be removed, got %+v", cfg.Relaxations)
+	}
+}
+
+func TestConfig_SaveAndLoad_Relaxations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guard.yaml")
+	until := time.Date(2026, 1, 2, 15, 30, 0, 0, time.UTC)
+	cfg := &Config{
+		GuardedContexts: []GuardedContext{{Name: "prod"}},
+		Relaxations:     []Relaxation{{Context: "prod", Until: until}},
+	}
+	if err := cfg.SaveTo(path); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	loaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if len(loaded.Relaxations) != 1 || !loaded.Relaxations[0].Until.Equal(until) {
+		t.Fatalf("expected the relaxation to round-trip, got %+v", loaded.Relaxations)
+	}
+
+	loaded.Clock = func() time.Time { return until.Add(-time.Minute) }
+	if loaded.IsGuarded("prod") {
+		t.Error("expected prod to be relaxed")
+	}
+	loaded.Clock = func() time.Time { return until }
+	if !loaded.IsGuarded("prod") {
+		t.Error("expected prod to be guarded at the expiry")
+	}
+}
@@ -0,0 +1,243 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScheduleMode is when a schedule guards: inside or outside its windows.
+type ScheduleMode string
+
+const (
+	// ScheduleDuring guards only inside the windows, e.g. a change freeze.
+	ScheduleDuring ScheduleMode = "during"
+	// ScheduleOutside guards only outside the windows, e.g. outside business hours.
+	ScheduleOutside ScheduleMode = "outside"
+)
+
+// Schedule restricts when an entry guards its contexts or some of their namespaces.
+type Schedule struct {
+	// Guard is "during" (default) or "outside" the windows.
+	Guard ScheduleMode `yaml:"guard,omitempty"`
+	// Namespaces limits the schedule to namespaces; empty applies it to the whole context.
+	Namespaces []string `yaml:"namespaces,omitempty"`
+	// Timezone of the windows, e.g. "Europe/Berlin"; local time by default.
+	Timezone string   `yaml:"timezone,omitempty"`
+	Windows  []Window `yaml:"windows"`
+}
+
+// Window is either recurring, starting at every Cron match and lasting Duration,
+// or a date range from From until Until.
+type Window struct {
+	// Cron is a five-field cron expression, e.g. "0 18 * * fri".
+	Cron     string        `yaml:"cron,omitempty"`
+	Duration time.Duration `yaml:"duration,omitempty"`
+	// From and Until are RFC 3339 times, "2006-01-02T15:04" or dates; either may be left open.
+	// A date Until includes the whole day.
+	From  string `yaml:"from,omitempty"`
+	Until string `yaml:"until,omitempty"`
+}
+
+// windowLayouts are the accepted From and Until formats, dates last.
+var windowLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", time.DateOnly}
+
+func (s *Schedule) validate() error {
+	switch s.Guard {
+	case "", ScheduleDuring, ScheduleOutside:
+	default:
+		return fmt.Errorf("invalid guard %q, expected %q or %q", s.Guard, ScheduleDuring, ScheduleOutside)
+	}
+	if _, err := s.location(); err != nil {
+		return err
+	}
+	for _, ns := range s.Namespaces {
+		if _, err := compilePattern(ns); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", ns, err)
+		}
+	}
+	if len(s.Windows) == 0 {
+		return errors.New("schedule requires windows")
+	}
+	for i := range s.Windows {
+		if err := s.Windows[i].validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+	}
+	return loc, nil
+}
+
+// AppliesTo checks if the schedule covers the namespace.
+// An empty namespace stands for every namespace of the context and is covered by every schedule.
+func (s *Schedule) AppliesTo(namespace string) bool {
+	if len(s.Namespaces) == 0 || namespace == "" {
+		return true
+	}
+	for _, ns := range s.Namespaces {
+		if MatchPattern(ns, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// Guards checks if the schedule guards at the time.
+func (s *Schedule) Guards(now time.Time) bool {
+	return s.inWindow(now) == (s.Guard != ScheduleOutside)
+}
+
+func (s *Schedule) inWindow(now time.Time) bool {
+	loc, err := s.location()
+	if err != nil {
+		// rejected by validate; guard rather than guess
+		return s.Guard != ScheduleOutside
+	}
+	now = now.In(loc)
+	for i := range s.Windows {
+		if s.Windows[i].contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the schedule, e.g. "during 0 18 * * fri for 62h0m0s (Europe/Berlin)".
+func (s *Schedule) String() string {
+	mode := s.Guard
+	if mode == "" {
+		mode = ScheduleDuring
+	}
+	windows := make([]string, 0, len(s.Windows))
+	for i := range s.Windows {
+		windows = append(windows, s.Windows[i].String())
+	}
+	str := string(mode) + " " + strings.Join(windows, ", ")
+	if s.Timezone != "" {
+		str += " (" + s.Timezone + ")"
+	}
+	if len(s.Namespaces) > 0 {
+		str += " in namespaces: " + strings.Join(s.Namespaces, ", ")
+	}
+	return str
+}
+
+func (w *Window) validate() error {
+	recurring := w.Cron != "" || w.Duration != 0
+	dated := w.From != "" || w.Until != ""
+	switch {
+	case recurring && dated:
+		return fmt.Errorf("window %q has both cron and from/until", w.String())
+	case !recurring && !dated:
+		return errors.New("window requires cron or from/until")
+	case recurring:
+		if _, err := parseCron(w.Cron); err != nil {
+			return err
+		}
+		if w.Duration <= 0 {
+			return fmt.Errorf("window %q requires a positive duration", w.String())
+		}
+		return nil
+	}
+	from, until, err := w.bounds(time.UTC)
+	if err != nil {
+		return err
+	}
+	if !from.IsZero() && !until.IsZero() && !from.Before(until) {
+		return fmt.Errorf("window %q ends before it starts", w.String())
+	}
+	return nil
+}
+
+// bounds parses From and Until in the location; an open bound is zero.
+func (w *Window) bounds(loc *time.Location) (from, until time.Time, err error) {
+	if w.From != "" {
+		if from, _, err = parseWindowTime(w.From, loc); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if w.Until != "" {
+		var date bool
+		if until, date, err = parseWindowTime(w.Until, loc); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if date {
+			until = until.AddDate(0, 0, 1)
+		}
+	}
+	return from, until, nil
+}
+
+// parseWindowTime parses a From or Until value in the location and reports whether it is a date.
+func parseWindowTime(s string, loc *time.Location) (time.Time, bool, error) {
+	for _, layout := range windowLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, layout == time.DateOnly, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid time %q, expected RFC 3339 or a date", s)
+}
+
+// contains checks if the window contains the time, taken in the schedule's location.
+func (w *Window) contains(now time.Time) bool {
+	if w.Cron != "" {
+		spec, err := parseCron(w.Cron)
+		if err != nil {
+			return false
+		}
+		_, ok := spec.lastStart(now, w.Duration)
+		return ok
+	}
+	from, until, err := w.bounds(now.Location())
+	if err != nil {
+		return false
+	}
+	return (from.IsZero() || !now.Before(from)) && (until.IsZero() || now.Before(until))
+}
+
+// String describes the window, e.g. "0 18 * * fri for 62h0m0s" or "2026-12-20 to 2027-01-03".
+func (w *Window) String() string {
+	if w.Cron != "" || w.Duration != 0 {
+		return w.Cron + " for " + w.Duration.String()
+	}
+	from, until := w.From, w.Until
+	if from == "" {
+		from = "the beginning"
+	}
+	if until == "" {
+		until = "further notice"
+	}
+	return from + " to " + until
+}
+
+// GuardsAt checks if the entry's schedules guard the namespace at the time.
+// Without applicable schedules the namespace is always guarded; with several, any guarding one is enough.
+// An empty namespace stands for every namespace, e.g. for cluster-scoped objects or --all-namespaces:
+// it is guarded while any schedule guards, or while some namespace is covered by no schedule at all.
+func (gc *GuardedContext) GuardsAt(namespace string, now time.Time) bool {
+	covered := false
+	for i := range gc.Schedules {
+		s := &gc.Schedules[i]
+		if !s.AppliesTo(namespace) {
+			continue
+		}
+		if s.Guards(now) {
+			return true
+		}
+		if namespace != "" || len(s.Namespaces) == 0 {
+			covered = true
+		}
+	}
+	return !covered
+}
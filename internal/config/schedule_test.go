@@ -0,0 +1,138 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_Guards(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+	weekend := Window{Cron: "0 18 * * fri", Duration: 62 * time.Hour}
+	freeze := Window{From: "2026-12-20", Until: "2027-01-03"}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		now      time.Time
+		expected bool
+	}{
+		{
+			name:     "during a weekend",
+			schedule: Schedule{Timezone: "Europe/Berlin", Windows: []Window{weekend}},
+			now:      time.Date(2026, 1, 3, 12, 0, 0, 0, berlin),
+			expected: true,
+		},
+		{
+			name:     "on a weekday",
+			schedule: Schedule{Timezone: "Europe/Berlin", Windows: []Window{weekend}},
+			now:      time.Date(2026, 1, 7, 12, 0, 0, 0, berlin),
+			expected: false,
+		},
+		{
+			name:     "timezone of the window",
+			schedule: Schedule{Timezone: "Europe/Berlin", Windows: []Window{weekend}},
+			// 17:30 UTC is 18:30 in Berlin
+			now:      time.Date(2026, 1, 2, 17, 30, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			name:     "outside a weekend",
+			schedule: Schedule{Guard: ScheduleOutside, Timezone: "Europe/Berlin", Windows: []Window{weekend}},
+			now:      time.Date(2026, 1, 7, 12, 0, 0, 0, berlin),
+			expected: true,
+		},
+		{
+			name:     "inside a date range",
+			schedule: Schedule{Timezone: "Europe/Berlin", Windows: []Window{freeze}},
+			now:      time.Date(2027, 1, 3, 23, 0, 0, 0, berlin),
+			expected: true,
+		},
+		{
+			name:     "after a date range",
+			schedule: Schedule{Timezone: "Europe/Berlin", Windows: []Window{freeze}},
+			now:      time.Date(2027, 1, 4, 0, 0, 0, 0, berlin),
+			expected: false,
+		},
+		{
+			name:     "open-ended range",
+			schedule: Schedule{Windows: []Window{{From: "2026-06-01T12:00:00Z"}}},
+			now:      time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			name:     "any window",
+			schedule: Schedule{Timezone: "Europe/Berlin", Windows: []Window{weekend, freeze}},
+			now:      time.Date(2026, 12, 22, 12, 0, 0, 0, berlin),
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.schedule.validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result := tt.schedule.Guards(tt.now); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestGuardedContext_GuardsAt(t *testing.T) {
+	now := time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC) // a Wednesday
+	gc := &GuardedContext{
+		Name: "prod",
+		Schedules: []Schedule{
+			{Timezone: "UTC", Windows: []Window{{Cron: "0 18 * * fri", Duration: 62 * time.Hour}}},
+			{Timezone: "UTC", Namespaces: []string{"payments"}, Windows: []Window{{Cron: "0 9 * * mon-fri", Duration: 8 * time.Hour}}},
+		},
+	}
+
+	evening := time.Date(2026, 1, 7, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		namespace string
+		now       time.Time
+		expected  bool
+	}{
+		// every namespace includes payments
+		{namespace: "", now: now, expected: true},
+		{namespace: "default", now: now, expected: false},
+		{namespace: "payments", now: now, expected: true},
+		{namespace: "", now: evening, expected: false},
+		{namespace: "payments", now: evening, expected: false},
+	}
+	for _, tt := range tests {
+		if result := gc.GuardsAt(tt.namespace, tt.now); result != tt.expected {
+			t.Errorf("namespace %q at %s: expected %v, got %v", tt.namespace, tt.now, tt.expected, result)
+		}
+	}
+
+	// namespaces without a schedule are always guarded, so every namespace is too
+	limited := &GuardedContext{Name: "prod", Schedules: gc.Schedules[1:]}
+	if !limited.GuardsAt("", evening) {
+		t.Error("expected every namespace to be guarded when some are not scheduled")
+	}
+
+	if !(&GuardedContext{Name: "prod"}).GuardsAt("default", now) {
+		t.Error("expected an entry without schedules to always guard")
+	}
+}
+
+func TestSchedule_String(t *testing.T) {
+	s := Schedule{
+		Timezone:   "Europe/Berlin",
+		Namespaces: []string{"payments"},
+		Windows: []Window{
+			{Cron: "0 18 * * fri", Duration: 62 * time.Hour},
+			{From: "2026-12-20", Until: "2027-01-03"},
+		},
+	}
+	expected := "during 0 18 * * fri for 62h0m0s, 2026-12-20 to 2027-01-03 (Europe/Berlin) in namespaces: payments"
+	if result := s.String(); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,422 @@
+package guard
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// globalFlags lists kubectl global flags by long name, mapped to whether they take a value.
+// Global flags may appear anywhere, including before the command.
+var globalFlags = map[string]bool{
+	"as":                       true,
+	"as-group":                 true,
+	"as-uid":                   true,
+	"cache-dir":                true,
+	"certificate-authority":    true,
+	"client-certificate":       true,
+	"client-key":               true,
+	"cluster":                  true,
+	"context":                  true,
+	"kubeconfig":               true,
+	"kuberc":                   true,
+	"namespace":                true,
+	"password":                 true,
+	"profile":                  true,
+	"profile-output":           true,
+	"request-timeout":          true,
+	"server":                   true,
+	"tls-server-name":          true,
+	"token":                    true,
+	"user":                     true,
+	"username":                 true,
+	"v":                        true,
+	"vmodule":                  true,
+	"log-backtrace-at":         true,
+	"log_backtrace_at":         true,
+	"log-dir":                  true,
+	"log_dir":                  true,
+	"log-file":                 true,
+	"log_file":                 true,
+	"log-file-max-size":        true,
+	"log_file_max_size":        true,
+	"log-flush-frequency":      true,
+	"stderrthreshold":          true,
+	"add-dir-header":           false,
+	"add_dir_header":           false,
+	"alsologtostderr":          false,
+	"disable-compression":      false,
+	"help":                     false,
+	"insecure-skip-tls-verify": false,
+	"logtostderr":              false,
+	"match-server-version":     false,
+	"one-output":               false,
+	"one_output":               false,
+	"skip-headers":             false,
+	"skip_headers":             false,
+	"skip-log-headers":         false,
+	"skip_log_headers":         false,
+	"warnings-as-errors":       false,
+}
+
+// commandValueFlags lists value-taking flags defined by individual kubectl commands.
+var commandValueFlags = map[string]bool{
+	"address":                      true,
+	"aggregation-rule":             true,
+	"allow-missing-template-keys":  false,
+	"annotations":                  true,
+	"api-group":                    true,
+	"api-version":                  true,
+	"applyset":                     true,
+	"cascade":                      false,
+	"certificate":                  true,
+	"chunk-size":                   true,
+	"cluster-ip":                   true,
+	"clusterrole":                  true,
+	"container":                    true,
+	"containers":                   true,
+	"copy-to":                      true,
+	"cpu":                          true,
+	"cpu-percent":                  true,
+	"current-replicas":             true,
+	"custom":                       true,
+	"docker-email":                 true,
+	"docker-password":              true,
+	"docker-server":                true,
+	"docker-username":              true,
+	"dry-run":                      false,
+	"env":                          true,
+	"env-from":                     true,
+	"external-ip":                  true,
+	"external-name":                true,
+	"field-manager":                true,
+	"field-selector":               true,
+	"filename":                     true,
+	"for":                          true,
+	"from":                         true,
+	"from-env-file":                true,
+	"from-file":                    true,
+	"from-literal":                 true,
+	"generator":                    true,
+	"grace-period":                 true,
+	"group":                        true,
+	"hard":                         true,
+	"hostport":                     true,
+	"image":                        true,
+	"image-pull-policy":            true,
+	"key":                          true,
+	"keys":                         true,
+	"kustomize":                    true,
+	"label-columns":                true,
+	"labels":                       true,
+	"limit-bytes":                  true,
+	"limits":                       true,
+	"load-balancer-ip":             true,
+	"max":                          true,
+	"max-log-requests":             true,
+	"min":                          true,
+	"min-available":                true,
+	"max-unavailable":              true,
+	"name":                         true,
+	"namespaces":                   true,
+	"node-port":                    true,
+	"output":                       true,
+	"output-directory":             true,
+	"override-type":                true,
+	"overrides":                    true,
+	"patch":                        true,
+	"patch-file":                   true,
+	"pod":                          true,
+	"pod-running-timeout":          true,
+	"pod-selector":                 true,
+	"port":                         true,
+	"prefix":                       true,
+	"priority-class-name":          true,
+	"protocol":                     true,
+	"prune-allowlist":              true,
+	"raw":                          true,
+	"replicas":                     true,
+	"requests":                     true,
+	"resource":                     true,
+	"resource-name":                true,
+	"resource-version":             true,
+	"restart":                      true,
+	"revision":                     true,
+	"role":                         true,
+	"rule":                         true,
+	"schedule":                     true,
+	"scopes":                       true,
+	"selector":                     true,
+	"serviceaccount":               true,
+	"session-affinity":             true,
+	"set-image":                    true,
+	"since":                        true,
+	"since-time":                   true,
+	"skip-wait-for-delete-timeout": true,
+	"sort-by":                      true,
+	"subresource":                  true,
+	"tail":                         true,
+	"target":                       true,
+	"target-container":             true,
+	"target-port":                  true,
+	"tcp":                          true,
+	"template":                     true,
+	"timeout":                      true,
+	"to-revision":                  true,
+	"type":                         true,
+	"validate":                     false,
+	"value":                        true,
+	"verb":                         true,
+}
+
+// shortFlags maps kubectl shorthand flags to their long names.
+var shortFlags = map[string]string{
+	"A": "all-namespaces",
+	"L": "label-columns",
+	"R": "recursive",
+	"c": "container",
+	"e": "env",
+	"f": "filename",
+	"h": "help",
+	"i": "stdin",
+	"k": "kustomize",
+	"l": "selector",
+	"n": "namespace",
+	"o": "output",
+	"p": "patch",
+	"q": "quiet",
+	"s": "server",
+	"t": "tty",
+	"v": "v",
+	"w": "watch",
+}
+
+// commandShortFlags lists shorthand flags that mean something else for a command.
+var commandShortFlags = map[string]map[string]string{
+	"logs": {
+		"f": "follow",
+		"p": "previous",
+	},
+}
+
+// takesValue reports whether the flag consumes the following argument when given without "=".
+// Unknown flags are treated as booleans, which is how the flags kubectl cares about behave.
+func takesValue(name string) bool {
+	if v, ok := globalFlags[name]; ok {
+		return v
+	}
+	return commandValueFlags[name]
+}
+
+// flagArg represents a flag occurrence on the command line.
+type flagArg struct {
+	name     string // long name without dashes
+	value    string
+	hasValue bool
+}
+
+// invocation represents a parsed kubectl command line.
+type invocation struct {
+	command     string
+	positionals []string // positional args after the command
+	flags       []flagArg
+	dashArgs    []string // args after the "--" terminator
+}
+
+// parseArgs parses kubectl args using kubectl's flag grammar.
+//
+// Before the command is found, kubectl (cobra) assumes every flag other than a known
+// global boolean consumes the following argument, so the same rule is applied here.
+// After the command, value-taking flags are looked up in the global and per-command tables.
+// Everything after "--" is left unparsed.
+func parseArgs(args []string) *invocation {
+	inv := &invocation{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		next := func() (string, bool) {
+			if i+1 < len(args) {
+				i++
+				return args[i], true
+			}
+			return "", false
+		}
+
+		switch {
+		case arg == "--":
+			inv.dashArgs = args[i+1:]
+			return inv
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			if !hasValue && inv.consumes(name) {
+				value, hasValue = next()
+			}
+			inv.flags = append(inv.flags, flagArg{name: name, value: value, hasValue: hasValue})
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			inv.parseShort(arg[1:], next)
+		case inv.command == "":
+			inv.command = arg
+		default:
+			inv.positionals = append(inv.positionals, arg)
+		}
+	}
+	return inv
+}
+
+// consumes reports whether a long flag without "=" consumes the next argument.
+func (inv *invocation) consumes(name string) bool {
+	if inv.command == "" {
+		v, ok := globalFlags[name]
+		return !ok || v
+	}
+	return takesValue(name)
+}
+
+// parseShort parses a shorthand flag or a cluster of shorthand flags such as "-it" or "-nprod".
+func (inv *invocation) parseShort(shorts string, next func() (string, bool)) {
+	if inv.command == "" && len(shorts) == 1 {
+		// cobra only looks at the "-x value" form when searching for the command
+		name := inv.longName(shorts)
+		value, hasValue := "", false
+		if v, ok := globalFlags[name]; !ok || v {
+			value, hasValue = next()
+		}
+		inv.flags = append(inv.flags, flagArg{name: name, value: value, hasValue: hasValue})
+		return
+	}
+
+	for j := 0; j < len(shorts); j++ {
+		name := inv.longName(shorts[j : j+1])
+		if shorts[j] == '=' || !takesValue(name) {
+			inv.flags = append(inv.flags, flagArg{name: name})
+			continue
+		}
+		value, hasValue := strings.TrimPrefix(shorts[j+1:], "="), true
+		if j+1 == len(shorts) {
+			value, hasValue = next()
+		}
+		inv.flags = append(inv.flags, flagArg{name: name, value: value, hasValue: hasValue})
+		return
+	}
+}
+
+func (inv *invocation) longName(short string) string {
+	if name, ok := commandShortFlags[inv.command][short]; ok {
+		return name
+	}
+	if name, ok := shortFlags[short]; ok {
+		return name
+	}
+	return short
+}
+
+// value returns the value of the last occurrence of the flag, as kubectl does.
+func (inv *invocation) value(name string) string {
+	value, _ := inv.lookup(name)
+	return value
+}
+
+// lookup returns the value of a flag's last occurrence and whether the flag is given with a value.
+func (inv *invocation) lookup(name string) (string, bool) {
+	value, found := "", false
+	for _, f := range inv.flags {
+		if f.name == name && f.hasValue {
+			value, found = f.value, true
+		}
+	}
+	return value, found
+}
+
+// values returns every value of a repeatable flag, splitting comma-separated lists.
+func (inv *invocation) values(name string) []string {
+	var values []string
+	for _, f := range inv.flags {
+		if f.name == name && f.hasValue {
+			for _, v := range strings.Split(f.value, ",") {
+				if v != "" {
+					values = append(values, v)
+				}
+			}
+		}
+	}
+	return values
+}
+
+// enabled reports whether a boolean flag is set to true by its last occurrence.
+func (inv *invocation) enabled(name string) bool {
+	enabled := false
+	for _, f := range inv.flags {
+		if f.name != name {
+			continue
+		}
+		enabled = true
+		if f.hasValue {
+			v, err := strconv.ParseBool(f.value)
+			enabled = err != nil || v
+		}
+	}
+	return enabled
+}
+
+// dryRun reports whether the last --dry-run flag requests a dry run.
+// The legacy bare --dry-run and --dry-run=true mean client.
+func (inv *invocation) dryRun() bool {
+	dryRun := false
+	for _, f := range inv.flags {
+		if f.name != "dry-run" {
+			continue
+		}
+		switch {
+		case !f.hasValue:
+			dryRun = true
+		case f.value == "true", f.value == "client", f.value == "server":
+			dryRun = true
+		default:
+			// "none", "false" and values kubectl rejects
+			dryRun = false
+		}
+	}
+	return dryRun
+}
+
+// secretFlags lists flags whose values are credentials, mapped to whether only the part of the value
+// after its key is secret, as in --from-literal=key=value.
+var secretFlags = map[string]bool{
+	"client-key":      false,
+	"docker-password": false,
+	"from-literal":    true,
+	"password":        false,
+	"token":           false,
+}
+
+// Redacted replaces credentials in RedactArgs.
+const Redacted = "REDACTED"
+
+// RedactArgs returns a copy of kubectl args with the values of credential flags, such as --token,
+// replaced by Redacted. Everything after "--" is left as is.
+func RedactArgs(args []string) []string {
+	redacted := slices.Clone(args)
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+		if arg == "--" {
+			break
+		}
+		name, value, inline := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		keyed, secret := secretFlags[name]
+		switch {
+		case !strings.HasPrefix(arg, "--") || !secret:
+		case inline:
+			redacted[i] = "--" + name + "=" + redactValue(value, keyed)
+		case i+1 < len(redacted):
+			i++
+			redacted[i] = redactValue(redacted[i], keyed)
+		}
+	}
+	return redacted
+}
+
+func redactValue(value string, keyed bool) string {
+	if key, _, ok := strings.Cut(value, "="); keyed && ok {
+		return key + "=" + Redacted
+	}
+	return Redacted
+}
@@ -0,0 +1,451 @@
+package guard
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseArgs_Namespace(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "short flag with space",
+			args:     []string{"delete", "pod", "-n", "production", "nginx"},
+			expected: "production",
+		},
+		{
+			name:     "short flag with equals",
+			args:     []string{"delete", "pod", "-n=production", "nginx"},
+			expected: "production",
+		},
+		{
+			name:     "long flag with space",
+			args:     []string{"delete", "pod", "--namespace", "production", "nginx"},
+			expected: "production",
+		},
+		{
+			name:     "long flag with equals",
+			args:     []string{"delete", "pod", "--namespace=production", "nginx"},
+			expected: "production",
+		},
+		{
+			name:     "no namespace flag",
+			args:     []string{"delete", "pod", "nginx"},
+			expected: "",
+		},
+		{
+			name:     "flag at end without value",
+			args:     []string{"delete", "pod", "-n"},
+			expected: "",
+		},
+		{
+			name:     "short flag with attached value",
+			args:     []string{"delete", "pod", "-nproduction", "nginx"},
+			expected: "production",
+		},
+		{
+			name:     "last occurrence wins",
+			args:     []string{"-n", "default", "delete", "pod", "-n", "production", "nginx"},
+			expected: "production",
+		},
+		{
+			name:     "flag after terminator belongs to the container command",
+			args:     []string{"exec", "nginx", "--", "ls", "-n", "production"},
+			expected: "",
+		},
+		{
+			name:     "flag value that looks like a flag",
+			args:     []string{"--as", "-n", "delete", "pod", "nginx"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseArgs(tt.args).value("namespace")
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseArgs_Context(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "flag with space",
+			args:     []string{"--context", "prod", "delete", "ns", "payments"},
+			expected: "prod",
+		},
+		{
+			name:     "flag with equals",
+			args:     []string{"delete", "ns", "payments", "--context=prod"},
+			expected: "prod",
+		},
+		{
+			name:     "no context flag",
+			args:     []string{"delete", "ns", "payments"},
+			expected: "",
+		},
+		{
+			name:     "flag at end without value",
+			args:     []string{"delete", "ns", "--context"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseArgs(tt.args).value("context")
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseArgs_Kubeconfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "flag with space",
+			args:     []string{"--kubeconfig", "/tmp/prod.yaml", "delete", "pod", "nginx"},
+			expected: "/tmp/prod.yaml",
+		},
+		{
+			name:     "flag with equals",
+			args:     []string{"delete", "pod", "nginx", "--kubeconfig=/tmp/prod.yaml"},
+			expected: "/tmp/prod.yaml",
+		},
+		{
+			name:     "no kubeconfig flag",
+			args:     []string{"delete", "pod", "nginx"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseArgs(tt.args).value("kubeconfig")
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseArgs_Command(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "simple command",
+			args:     []string{"delete", "pod", "nginx"},
+			expected: "delete",
+		},
+		{
+			name:     "with flags first",
+			args:     []string{"-n", "production", "delete", "pod", "nginx"},
+			expected: "delete",
+		},
+		{
+			name:     "only flags",
+			args:     []string{"-n", "production"},
+			expected: "",
+		},
+		{
+			name:     "empty args",
+			args:     []string{},
+			expected: "",
+		},
+		{
+			name:     "impersonation flag before command",
+			args:     []string{"--as", "admin", "delete", "pod", "x"},
+			expected: "delete",
+		},
+		{
+			name:     "multiple global flags before command",
+			args:     []string{"--request-timeout", "5s", "--as-group", "system:masters", "--token", "t", "-s", "https://k8s", "--certificate-authority", "ca.crt", "delete", "pod", "x"},
+			expected: "delete",
+		},
+		{
+			name:     "verbosity short flag",
+			args:     []string{"-v", "6", "delete", "pod", "x"},
+			expected: "delete",
+		},
+		{
+			name:     "verbosity attached value",
+			args:     []string{"-v6", "delete", "pod", "x"},
+			expected: "delete",
+		},
+		{
+			name:     "global boolean flag before command",
+			args:     []string{"--insecure-skip-tls-verify", "delete", "pod", "x"},
+			expected: "delete",
+		},
+		{
+			name:     "flag value named like a command",
+			args:     []string{"--user", "get", "delete", "pod", "x"},
+			expected: "delete",
+		},
+		{
+			name:     "command flag with value after command",
+			args:     []string{"delete", "--grace-period", "0", "pod", "x"},
+			expected: "delete",
+		},
+		{
+			name:     "terminator before command",
+			args:     []string{"--", "delete", "pod", "x"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseArgs(tt.args).command
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseArgs_Positionals(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		positionals []string
+		dashArgs    []string
+	}{
+		{
+			name:        "value flags are skipped",
+			args:        []string{"delete", "-l", "app=web", "--grace-period", "0", "pods", "--force"},
+			positionals: []string{"pods"},
+		},
+		{
+			name:        "boolean flags do not consume",
+			args:        []string{"rollout", "--watch", "restart", "deploy/web"},
+			positionals: []string{"restart", "deploy/web"},
+		},
+		{
+			name:        "optional value flags do not consume",
+			args:        []string{"delete", "--dry-run", "pod", "nginx"},
+			positionals: []string{"pod", "nginx"},
+		},
+		{
+			name:        "shorthand cluster",
+			args:        []string{"exec", "-itc", "app", "nginx", "--", "sh", "-c", "ls"},
+			positionals: []string{"nginx"},
+			dashArgs:    []string{"sh", "-c", "ls"},
+		},
+		{
+			name:        "command specific shorthand",
+			args:        []string{"logs", "-f", "nginx", "-p"},
+			positionals: []string{"nginx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := parseArgs(tt.args)
+			if !slices.Equal(inv.positionals, tt.positionals) {
+				t.Errorf("expected positionals %q, got %q", tt.positionals, inv.positionals)
+			}
+			if !slices.Equal(inv.dashArgs, tt.dashArgs) {
+				t.Errorf("expected dash args %q, got %q", tt.dashArgs, inv.dashArgs)
+			}
+		})
+	}
+}
+
+func TestParseArgs_DryRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{
+			name:     "client",
+			args:     []string{"delete", "pod", "x", "--dry-run=client"},
+			expected: true,
+		},
+		{
+			name:     "server",
+			args:     []string{"apply", "-f", "deploy.yaml", "--dry-run=server"},
+			expected: true,
+		},
+		{
+			name:     "legacy bare flag",
+			args:     []string{"apply", "--dry-run", "-f", "deploy.yaml"},
+			expected: true,
+		},
+		{
+			name:     "legacy true",
+			args:     []string{"apply", "-f", "deploy.yaml", "--dry-run=true"},
+			expected: true,
+		},
+		{
+			name:     "none",
+			args:     []string{"apply", "-f", "deploy.yaml", "--dry-run=none"},
+			expected: false,
+		},
+		{
+			name:     "last occurrence wins",
+			args:     []string{"apply", "-f", "deploy.yaml", "--dry-run=server", "--dry-run=none"},
+			expected: false,
+		},
+		{
+			name:     "invalid value",
+			args:     []string{"apply", "-f", "deploy.yaml", "--dry-run=maybe"},
+			expected: false,
+		},
+		{
+			name:     "space separated value is not consumed",
+			args:     []string{"delete", "--dry-run", "client", "pod", "x"},
+			expected: true,
+		},
+		{
+			name:     "flag after terminator",
+			args:     []string{"exec", "nginx", "--", "tool", "--dry-run"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := parseArgs(tt.args).dryRun(); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+// globalFlagForms returns every way a global flag can be spelled with the given value.
+func globalFlagForms(value string) [][]string {
+	var forms [][]string
+	for name, hasValue := range globalFlags {
+		if !hasValue {
+			forms = append(forms, []string{"--" + name}, []string{"--" + name + "=true"})
+			continue
+		}
+		forms = append(forms, []string{"--" + name, value}, []string{"--" + name + "=" + value})
+	}
+	for short, name := range shortFlags {
+		hasValue, ok := globalFlags[name]
+		if !ok || !hasValue {
+			continue
+		}
+		forms = append(forms, []string{"-" + short, value}, []string{"-" + short + "=" + value})
+		if value != "" {
+			forms = append(forms, []string{"-" + short + value})
+		}
+	}
+	return forms
+}
+
+// insertAt returns a copy of args with extra inserted at position i.
+func insertAt(args []string, i int, extra []string) []string {
+	return slices.Concat(args[:i], extra, args[i:])
+}
+
+func TestParseArgs_CommandGlobalFlagPermutations(t *testing.T) {
+	verbs := []string{"delete", "apply", "patch", "replace", "scale", "drain"}
+	values := []string{"admin", "delete", "get", "-n", "--", "6"}
+
+	for _, verb := range verbs {
+		base := []string{verb, "pod", "x"}
+		for _, value := range values {
+			forms := globalFlagForms(value)
+			for _, first := range forms {
+				for pos := 0; pos <= len(base); pos++ {
+					args := insertAt(base, pos, first)
+					if got := parseArgs(args).command; got != verb {
+						t.Fatalf("command of %q = %q, expected %q", args, got, verb)
+					}
+				}
+				for _, second := range forms {
+					args := slices.Concat(first, second, base)
+					if got := parseArgs(args).command; got != verb {
+						t.Fatalf("command of %q = %q, expected %q", args, got, verb)
+					}
+				}
+			}
+		}
+	}
+}
+
+func FuzzParseArgs_Command(f *testing.F) {
+	f.Add(uint8(0), "admin", uint8(0))
+	f.Add(uint8(3), "get", uint8(1))
+	f.Add(uint8(7), "--", uint8(3))
+	f.Add(uint8(11), "-n", uint8(2))
+
+	f.Fuzz(func(t *testing.T, formIndex uint8, value string, pos uint8) {
+		base := []string{"delete", "namespace", "payments"}
+		forms := globalFlagForms(value)
+		slices.SortFunc(forms, slices.Compare)
+		form := forms[int(formIndex)%len(forms)]
+		args := insertAt(base, int(pos)%(len(base)+1), form)
+		if got := parseArgs(args).command; got != "delete" {
+			t.Errorf("command of %q = %q, expected %q", args, got, "delete")
+		}
+	})
+}
+
+func TestRedactArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "inline value",
+			args:     []string{"--token=s3cr3t", "get", "pods"},
+			expected: []string{"--token=REDACTED", "get", "pods"},
+		},
+		{
+			name:     "separate value",
+			args:     []string{"--context", "prod", "--password", "s3cr3t", "--client-key", "key.pem", "delete", "pod", "x"},
+			expected: []string{"--context", "prod", "--password", "REDACTED", "--client-key", "REDACTED", "delete", "pod", "x"},
+		},
+		{
+			name:     "literal keeps its key",
+			args:     []string{"create", "secret", "generic", "db", "--from-literal=password=s3cr3t", "--from-literal", "user=admin"},
+			expected: []string{"create", "secret", "generic", "db", "--from-literal=password=REDACTED", "--from-literal", "user=REDACTED"},
+		},
+		{
+			name:     "flag without value",
+			args:     []string{"get", "pods", "--token"},
+			expected: []string{"get", "pods", "--token"},
+		},
+		{
+			name:     "after the separator",
+			args:     []string{"exec", "web", "--", "login", "--token", "s3cr3t"},
+			expected: []string{"exec", "web", "--", "login", "--token", "s3cr3t"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := slices.Clone(tt.args)
+			if result := RedactArgs(args); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			if !slices.Equal(args, tt.args) {
+				t.Errorf("expected the args to be left as is, got %q", args)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package guard
+
+import (
+	"strings"
+
+	"github.com/sivchari/kubectl-guard/internal/config"
+)
+
+// blastRadius classifies how many objects the invocation may reach.
+func blastRadius(inv *invocation, objects []Object) config.BlastRadius {
+	if inv.enabled("all-namespaces") {
+		return config.BlastRadiusAllNamespaces
+	}
+	if inv.enabled("all") {
+		return config.BlastRadiusAll
+	}
+	for _, o := range objects {
+		// the "all" category, or a type without a name such as "rollout restart deploy"
+		if o.Source == "" && (o.Name == "" || o.Resource.Name == allCategory) {
+			return selectorRadius(inv)
+		}
+	}
+	if _, ok := inv.lookup("selector"); ok {
+		return selectorRadius(inv)
+	}
+	return config.BlastRadiusNamed
+}
+
+// selectorRadius classifies the label and field selectors of the invocation.
+// Selectors that are empty or only check for the existence of labels, e.g. "-l app", match everything.
+func selectorRadius(inv *invocation) config.BlastRadius {
+	if fields := inv.value("field-selector"); fields != "" {
+		return config.BlastRadiusSelector
+	}
+	labels, ok := inv.lookup("selector")
+	if !ok || isExistenceSelector(labels) {
+		return config.BlastRadiusAll
+	}
+	return config.BlastRadiusSelector
+}
+
+// isExistenceSelector checks if every requirement of a label selector is "key" or "!key".
+func isExistenceSelector(selector string) bool {
+	for _, req := range splitSelector(selector) {
+		key := strings.TrimPrefix(strings.TrimSpace(req), "!")
+		if strings.ContainsAny(key, "=!() \t") {
+			return false
+		}
+	}
+	return true
+}
+
+// splitSelector splits a label selector into requirements, keeping "in (a,b)" sets together.
+func splitSelector(selector string) []string {
+	var reqs []string
+	depth, start := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				reqs = append(reqs, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(reqs, selector[start:])
+}
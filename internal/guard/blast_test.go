@@ -0,0 +1,113 @@
+package guard
+
+import (
+	"testing"
+
+	"github.com/sivchari/kubectl-guard/internal/config"
+)
+
+func TestBlastRadius(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected config.BlastRadius
+	}{
+		{args: []string{"delete", "pod", "nginx"}, expected: config.BlastRadiusNamed},
+		{args: []string{"delete", "pods/a", "pods/b"}, expected: config.BlastRadiusNamed},
+		{args: []string{"apply", "-f", "deploy.yaml"}, expected: config.BlastRadiusNamed},
+		{args: []string{"delete", "pods", "-l", "app=web"}, expected: config.BlastRadiusSelector},
+		{args: []string{"delete", "pods", "-l", "tier in (web,api)"}, expected: config.BlastRadiusSelector},
+		{args: []string{"delete", "pods", "--field-selector", "status.phase=Failed"}, expected: config.BlastRadiusSelector},
+		{args: []string{"delete", "pods", "--all"}, expected: config.BlastRadiusAll},
+		{args: []string{"delete", "deploy", "-l", "app"}, expected: config.BlastRadiusAll},
+		{args: []string{"delete", "deploy", "-l", "app,!canary"}, expected: config.BlastRadiusAll},
+		{args: []string{"delete", "deploy", "--selector="}, expected: config.BlastRadiusAll},
+		{args: []string{"delete", "all", "--all"}, expected: config.BlastRadiusAll},
+		{args: []string{"delete", "all", "-l", "app=web"}, expected: config.BlastRadiusSelector},
+		{args: []string{"rollout", "restart", "deploy"}, expected: config.BlastRadiusAll},
+		{args: []string{"delete", "pods", "--all", "-A"}, expected: config.BlastRadiusAllNamespaces},
+		{args: []string{"delete", "pods", "-l", "app=web", "--all-namespaces"}, expected: config.BlastRadiusAllNamespaces},
+	}
+
+	for _, tt := range tests {
+		inv := parseArgs(tt.args)
+		if result := blastRadius(inv, builtinResolver.objects(inv, "default")); result != tt.expected {
+			t.Errorf("blastRadius(%q) = %s, expected %s", tt.args, result, tt.expected)
+		}
+	}
+}
+
+func TestGuard_Check_BlastRadius(t *testing.T) {
+	setupKubeconfig(t)
+
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{
+				Name:          "prod",
+				AllowCommands: []string{"delete"},
+				BlastRadius: map[config.BlastRadius]config.Action{
+					config.BlastRadiusSelector: config.ActionBlock,
+					config.BlastRadiusAll:      config.ActionDeny,
+				},
+			},
+			{Name: "staging", Namespaces: []string{"critical"}, BlastRadius: map[config.BlastRadius]config.Action{
+				config.BlastRadiusAll: config.ActionDeny,
+			}},
+		},
+	}
+	g := New(cfg)
+
+	tests := []struct {
+		name    string
+		args    []string
+		blocked bool
+		denied  bool
+	}{
+		{
+			name: "named objects are left to the command policy",
+			args: []string{"--context", "prod", "delete", "pod", "nginx"},
+		},
+		{
+			name:    "selector requires an override",
+			args:    []string{"--context", "prod", "delete", "pods", "-l", "app=web"},
+			blocked: true,
+		},
+		{
+			name:    "all is denied",
+			args:    []string{"--context", "prod", "delete", "pods", "--all"},
+			blocked: true,
+			denied:  true,
+		},
+		{
+			name:    "existence-only selector counts as all",
+			args:    []string{"--context", "prod", "delete", "deploy", "-l", "app"},
+			blocked: true,
+			denied:  true,
+		},
+		{
+			name: "read commands are not escalated",
+			args: []string{"--context", "prod", "get", "pods", "-l", "app"},
+		},
+		{
+			name: "unguarded namespace is not escalated",
+			args: []string{"--context", "staging", "-n", "default", "delete", "pods", "--all"},
+		},
+		{
+			name:    "guarded namespace is escalated",
+			args:    []string{"--context", "staging", "delete", "pods", "--all"},
+			blocked: true,
+			denied:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Blocked != tt.blocked || result.Denied != tt.denied {
+				t.Errorf("expected blocked=%v denied=%v, got blocked=%v denied=%v", tt.blocked, tt.denied, result.Blocked, result.Denied)
+			}
+		})
+	}
+}
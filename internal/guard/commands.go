@@ -0,0 +1,150 @@
+package guard
+
+import (
+	"strings"
+)
+
+// CommandClass classifies what a kubectl command does to the cluster.
+type CommandClass string
+
+const (
+	// ClassRead is a command that only reads cluster or local state.
+	ClassRead CommandClass = "read"
+	// ClassAccess is a command that opens a session into workloads or the API server.
+	ClassAccess CommandClass = "access"
+	// ClassMutate is a command that creates, changes or deletes resources.
+	ClassMutate CommandClass = "mutate"
+)
+
+// Commands is the kubectl command taxonomy keyed on "command" or "command subcommand".
+// A subcommand missing from the table is classified like its parent command.
+var Commands = map[string]CommandClass{
+	// basic commands
+	"create":       ClassMutate,
+	"expose":       ClassMutate,
+	"run":          ClassMutate,
+	"set":          ClassMutate,
+	"explain":      ClassRead,
+	"get":          ClassRead,
+	"edit":         ClassMutate,
+	"delete":       ClassMutate,
+	"events":       ClassRead,
+	"kustomize":    ClassRead,
+	"diff":         ClassRead,
+	"wait":         ClassRead,
+	"alpha":        ClassRead,
+	"version":      ClassRead,
+	"completion":   ClassRead,
+	"options":      ClassRead,
+	"help":         ClassRead,
+	"api-versions": ClassRead,
+
+	// deploy commands
+	"rollout":         ClassMutate,
+	"rollout history": ClassRead,
+	"rollout pause":   ClassMutate,
+	"rollout restart": ClassMutate,
+	"rollout resume":  ClassMutate,
+	"rollout status":  ClassRead,
+	"rollout undo":    ClassMutate,
+	"scale":           ClassMutate,
+	"autoscale":       ClassMutate,
+
+	// cluster management commands
+	"certificate":         ClassMutate,
+	"certificate approve": ClassMutate,
+	"certificate deny":    ClassMutate,
+	"cluster-info":        ClassRead,
+	"cluster-info dump":   ClassRead,
+	"top":                 ClassRead,
+	"cordon":              ClassMutate,
+	"uncordon":            ClassMutate,
+	"drain":               ClassMutate,
+	"taint":               ClassMutate,
+
+	// troubleshooting and debugging commands
+	"describe":     ClassRead,
+	"logs":         ClassRead,
+	"attach":       ClassAccess,
+	"exec":         ClassAccess,
+	"port-forward": ClassAccess,
+	"proxy":        ClassAccess,
+	"cp":           ClassAccess,
+	// debug adds ephemeral containers, copies pods with --copy-to and starts pods on nodes
+	"debug": ClassMutate,
+
+	// advanced commands
+	"apply":                   ClassMutate,
+	"apply edit-last-applied": ClassMutate,
+	"apply set-last-applied":  ClassMutate,
+	"apply view-last-applied": ClassRead,
+	"patch":                   ClassMutate,
+	"replace":                 ClassMutate,
+	"auth":                    ClassRead,
+	"auth can-i":              ClassRead,
+	"auth reconcile":          ClassMutate,
+	"auth whoami":             ClassRead,
+	"api-resources":           ClassRead,
+
+	// settings commands
+	"label":    ClassMutate,
+	"annotate": ClassMutate,
+
+	// commands that only touch local files
+	"config": ClassRead,
+	"plugin": ClassRead,
+}
+
+// subcommands lists commands whose first positional argument is a subcommand.
+var subcommands = map[string]bool{
+	"apply":        true,
+	"auth":         true,
+	"certificate":  true,
+	"cluster-info": true,
+	"config":       true,
+	"create":       true,
+	"plugin":       true,
+	"rollout":      true,
+	"set":          true,
+	"top":          true,
+}
+
+// listFlagCommands lists commands that only print resources when --list is set.
+var listFlagCommands = map[string]bool{
+	"label":    true,
+	"annotate": true,
+}
+
+// ClassifyCommand returns the class of a command path such as "rollout status".
+// Unknown commands, such as plugins or verbs newer than the table, may change anything and are
+// classified as mutating; kubectl without a command only prints its usage.
+func ClassifyCommand(path string) CommandClass {
+	if class, ok := Commands[path]; ok {
+		return class
+	}
+	cmd, _, _ := strings.Cut(path, " ")
+	if class, ok := Commands[cmd]; ok {
+		return class
+	}
+	if cmd == "" {
+		return ClassRead
+	}
+	return ClassMutate
+}
+
+// commandPath returns the command followed by its subcommand for commands that have subcommands.
+func (inv *invocation) commandPath() string {
+	if subcommands[inv.command] && len(inv.positionals) > 0 {
+		return inv.command + " " + inv.positionals[0]
+	}
+	return inv.command
+}
+
+// class classifies the invocation, taking flags that change a command's behavior into account.
+// Note that --raw keeps the class of its verb: "get --raw" reads while "replace --raw" mutates.
+func (inv *invocation) class() CommandClass {
+	if listFlagCommands[inv.command] && inv.enabled("list") {
+		return ClassRead
+	}
+	return ClassifyCommand(inv.commandPath())
+}
@@ -0,0 +1,113 @@
+package guard
+
+import (
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		args     []string
+		path     string
+		expected CommandClass
+	}{
+		// basic commands
+		{args: []string{"create", "-f", "deploy.yaml"}, path: "create", expected: ClassMutate},
+		{args: []string{"create", "deployment", "web", "--image=nginx"}, path: "create deployment", expected: ClassMutate},
+		{args: []string{"create", "secret", "generic", "s", "--from-literal=a=b"}, path: "create secret", expected: ClassMutate},
+		{args: []string{"expose", "deploy", "web", "--port", "80"}, path: "expose", expected: ClassMutate},
+		{args: []string{"run", "debug", "--image", "busybox"}, path: "run", expected: ClassMutate},
+		{args: []string{"set", "image", "deploy/web", "web=nginx:2"}, path: "set image", expected: ClassMutate},
+		{args: []string{"explain", "pods"}, path: "explain", expected: ClassRead},
+		{args: []string{"get", "pods"}, path: "get", expected: ClassRead},
+		{args: []string{"get", "--raw", "/healthz"}, path: "get", expected: ClassRead},
+		{args: []string{"edit", "deploy/web"}, path: "edit", expected: ClassMutate},
+		{args: []string{"delete", "pod", "nginx"}, path: "delete", expected: ClassMutate},
+		{args: []string{"events"}, path: "events", expected: ClassRead},
+		{args: []string{"kustomize", "overlays/prod"}, path: "kustomize", expected: ClassRead},
+		{args: []string{"diff", "-f", "deploy.yaml"}, path: "diff", expected: ClassRead},
+		{args: []string{"wait", "--for=condition=Ready", "pod/nginx"}, path: "wait", expected: ClassRead},
+		{args: []string{"version"}, path: "version", expected: ClassRead},
+
+		// deploy commands
+		{args: []string{"rollout", "status", "deploy/web"}, path: "rollout status", expected: ClassRead},
+		{args: []string{"rollout", "history", "deploy/web"}, path: "rollout history", expected: ClassRead},
+		{args: []string{"rollout", "restart", "deploy/web"}, path: "rollout restart", expected: ClassMutate},
+		{args: []string{"rollout", "undo", "deploy/web"}, path: "rollout undo", expected: ClassMutate},
+		{args: []string{"rollout", "pause", "deploy/web"}, path: "rollout pause", expected: ClassMutate},
+		{args: []string{"rollout", "resume", "deploy/web"}, path: "rollout resume", expected: ClassMutate},
+		{args: []string{"rollout", "unknown", "deploy/web"}, path: "rollout unknown", expected: ClassMutate},
+		{args: []string{"-n", "prod", "rollout", "--timeout", "5s", "status", "deploy/web"}, path: "rollout status", expected: ClassRead},
+		{args: []string{"scale", "deploy/web", "--replicas=0"}, path: "scale", expected: ClassMutate},
+		{args: []string{"autoscale", "deploy/web", "--max", "5"}, path: "autoscale", expected: ClassMutate},
+
+		// cluster management commands
+		{args: []string{"certificate", "approve", "csr-1"}, path: "certificate approve", expected: ClassMutate},
+		{args: []string{"certificate", "deny", "csr-1"}, path: "certificate deny", expected: ClassMutate},
+		{args: []string{"cluster-info"}, path: "cluster-info", expected: ClassRead},
+		{args: []string{"cluster-info", "dump"}, path: "cluster-info dump", expected: ClassRead},
+		{args: []string{"top", "pods"}, path: "top pods", expected: ClassRead},
+		{args: []string{"cordon", "node-1"}, path: "cordon", expected: ClassMutate},
+		{args: []string{"uncordon", "node-1"}, path: "uncordon", expected: ClassMutate},
+		{args: []string{"drain", "node-1", "--ignore-daemonsets"}, path: "drain", expected: ClassMutate},
+		{args: []string{"taint", "nodes", "node-1", "key=value:NoSchedule"}, path: "taint", expected: ClassMutate},
+
+		// troubleshooting and debugging commands
+		{args: []string{"describe", "pod", "nginx"}, path: "describe", expected: ClassRead},
+		{args: []string{"logs", "nginx", "-f"}, path: "logs", expected: ClassRead},
+		{args: []string{"attach", "nginx"}, path: "attach", expected: ClassAccess},
+		{args: []string{"exec", "-it", "nginx", "--", "sh"}, path: "exec", expected: ClassAccess},
+		{args: []string{"port-forward", "svc/web", "8080:80"}, path: "port-forward", expected: ClassAccess},
+		{args: []string{"proxy"}, path: "proxy", expected: ClassAccess},
+		{args: []string{"cp", "nginx:/tmp/a", "a"}, path: "cp", expected: ClassAccess},
+		{args: []string{"debug", "nginx", "--image", "busybox"}, path: "debug", expected: ClassMutate},
+		{args: []string{"debug", "node/worker-1", "-it", "--image", "busybox"}, path: "debug", expected: ClassMutate},
+		{args: []string{"debug", "nginx", "--copy-to", "nginx-debug"}, path: "debug", expected: ClassMutate},
+
+		// advanced commands
+		{args: []string{"apply", "-f", "deploy.yaml"}, path: "apply", expected: ClassMutate},
+		{args: []string{"apply", "view-last-applied", "deploy/web"}, path: "apply view-last-applied", expected: ClassRead},
+		{args: []string{"apply", "set-last-applied", "-f", "deploy.yaml"}, path: "apply set-last-applied", expected: ClassMutate},
+		{args: []string{"patch", "deploy/web", "-p", "{}"}, path: "patch", expected: ClassMutate},
+		{args: []string{"replace", "-f", "deploy.yaml"}, path: "replace", expected: ClassMutate},
+		{args: []string{"replace", "--raw", "/api/v1/namespaces/prod", "-f", "ns.json"}, path: "replace", expected: ClassMutate},
+		{args: []string{"auth", "can-i", "delete", "pods"}, path: "auth can-i", expected: ClassRead},
+		{args: []string{"auth", "whoami"}, path: "auth whoami", expected: ClassRead},
+		{args: []string{"auth", "reconcile", "-f", "rbac.yaml"}, path: "auth reconcile", expected: ClassMutate},
+		{args: []string{"api-resources"}, path: "api-resources", expected: ClassRead},
+
+		// settings commands
+		{args: []string{"label", "pod", "nginx", "app=web"}, path: "label", expected: ClassMutate},
+		{args: []string{"label", "pod", "nginx", "--list"}, path: "label", expected: ClassRead},
+		{args: []string{"annotate", "pod", "nginx", "a=b"}, path: "annotate", expected: ClassMutate},
+		{args: []string{"annotate", "pod", "nginx", "--list"}, path: "annotate", expected: ClassRead},
+		{args: []string{"config", "use-context", "prod"}, path: "config use-context", expected: ClassRead},
+
+		// plugins and unknown commands
+		{args: []string{"neat", "get", "pods"}, path: "neat", expected: ClassMutate},
+		{args: []string{"--context", "prod"}, path: "", expected: ClassRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			inv := parseArgs(tt.args)
+			if path := inv.commandPath(); path != tt.path {
+				t.Errorf("expected command path %q, got %q", tt.path, path)
+			}
+			if class := inv.class(); class != tt.expected {
+				t.Errorf("expected class %q for %q, got %q", tt.expected, tt.args, class)
+			}
+		})
+	}
+}
+
+func TestClassifyCommand_Subcommands(t *testing.T) {
+	if ClassifyCommand("rollout status") == ClassMutate {
+		t.Error("expected 'rollout status' to be safe")
+	}
+	if ClassifyCommand("rollout restart") != ClassMutate {
+		t.Error("expected 'rollout restart' to be destructive")
+	}
+	if ClassifyCommand("set env") != ClassMutate {
+		t.Error("expected 'set env' to be destructive")
+	}
+}
@@ -0,0 +1,164 @@
+package guard
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CacheDirEnv overrides kubectl's default cache directory.
+const CacheDirEnv = "KUBECACHEDIR"
+
+// illegalFileCharacters matches the characters kubectl replaces in the discovery cache directory name.
+var illegalFileCharacters = regexp.MustCompile(`[^(\w/.)]`)
+
+type apiGroupList struct {
+	Groups []struct {
+		Name             string `json:"name"`
+		PreferredVersion struct {
+			GroupVersion string `json:"groupVersion"`
+		} `json:"preferredVersion"`
+		Versions []struct {
+			GroupVersion string `json:"groupVersion"`
+		} `json:"versions"`
+	} `json:"groups"`
+}
+
+type apiResourceList struct {
+	GroupVersion string `json:"groupVersion"`
+	Resources    []struct {
+		Name         string   `json:"name"`
+		SingularName string   `json:"singularName"`
+		Namespaced   bool     `json:"namespaced"`
+		Kind         string   `json:"kind"`
+		ShortNames   []string `json:"shortNames"`
+	} `json:"resources"`
+}
+
+// DefaultCacheDir returns kubectl's cache directory: KUBECACHEDIR or ~/.kube/cache.
+func DefaultCacheDir() string {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "cache")
+}
+
+// DiscoveryCacheDir returns the directory kubectl caches discovery of the server in.
+func DiscoveryCacheDir(cacheDir, server string) string {
+	host := strings.Replace(strings.Replace(server, "https://", "", 1), "http://", "", 1)
+	return filepath.Join(cacheDir, "discovery", illegalFileCharacters.ReplaceAllString(host, "_"))
+}
+
+// LoadDiscoveryCache loads the resources of a server from kubectl's on-disk discovery cache.
+// The core group comes first, followed by the other groups in the server's priority order.
+func LoadDiscoveryCache(cacheDir, server string) ([]Resource, error) {
+	dir := DiscoveryCacheDir(cacheDir, server)
+
+	paths := []string{filepath.Join(dir, "v1", "serverresources.json")}
+	groupPaths, err := discoveryGroupPaths(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, groupPaths...)
+
+	var resources []Resource
+	seen := map[string]bool{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		var list apiResourceList
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+
+		group := ""
+		if g, _, ok := strings.Cut(list.GroupVersion, "/"); ok {
+			group = g
+		}
+		for _, r := range list.Resources {
+			// skip subresources such as "deployments/scale"
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			key := r.Name + "." + group
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			resources = append(resources, Resource{
+				Name:       r.Name,
+				Singular:   strings.ToLower(r.SingularName),
+				ShortNames: r.ShortNames,
+				Kind:       r.Kind,
+				Group:      group,
+				Namespaced: r.Namespaced,
+			})
+		}
+	}
+	return resources, nil
+}
+
+// discoveryGroupPaths lists the cached resource files of the non-core groups.
+// servergroups.json gives the priority order; without it every cached file is used in lexical order.
+func discoveryGroupPaths(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "servergroups.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		paths, err := filepath.Glob(filepath.Join(dir, "*", "*", "serverresources.json"))
+		sort.Strings(paths)
+		return paths, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var groups apiGroupList
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, g := range groups.Groups {
+		if g.Name == "" {
+			continue
+		}
+		versions := []string{g.PreferredVersion.GroupVersion}
+		for _, v := range g.Versions {
+			if v.GroupVersion != g.PreferredVersion.GroupVersion {
+				versions = append(versions, v.GroupVersion)
+			}
+		}
+		for _, gv := range versions {
+			if gv != "" {
+				paths = append(paths, filepath.Join(dir, filepath.FromSlash(gv), "serverresources.json"))
+			}
+		}
+	}
+	return paths, nil
+}
+
+// NewDiscoveryResolver creates a Resolver for the server from kubectl's discovery cache.
+// The built-in resources are used for anything the cache does not know, or when it is missing.
+func NewDiscoveryResolver(cacheDir, server string) *Resolver {
+	if cacheDir == "" || server == "" {
+		return builtinResolver
+	}
+	resources, err := LoadDiscoveryCache(cacheDir, server)
+	if err != nil || len(resources) == 0 {
+		return builtinResolver
+	}
+	return NewResolver(append(resources, builtinResources...))
+}
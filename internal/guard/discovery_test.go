@@ -0,0 +1,184 @@
+package guard
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sivchari/kubectl-guard/internal/config"
+)
+
+func TestDiscoveryCacheDir(t *testing.T) {
+	tests := []struct {
+		server   string
+		expected string
+	}{
+		{server: "https://prod.example.com", expected: "/cache/discovery/prod.example.com"},
+		{server: "https://prod.example.com:6443", expected: "/cache/discovery/prod.example.com_6443"},
+		{server: "http://127.0.0.1:8080", expected: "/cache/discovery/127.0.0.1_8080"},
+	}
+
+	for _, tt := range tests {
+		if result := DiscoveryCacheDir("/cache", tt.server); result != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.server, tt.expected, result)
+		}
+	}
+}
+
+func TestLoadDiscoveryCache(t *testing.T) {
+	cacheDir := filepath.Join("testdata", "cache")
+
+	resources, err := LoadDiscoveryCache(cacheDir, "https://prod.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names := make([]string, 0, len(resources))
+	for _, r := range resources {
+		names = append(names, r.String())
+	}
+	expected := []string{
+		"events", "namespaces", "pods",
+		"deployments.apps",
+		"events.events.k8s.io",
+		"certificates.cert-manager.io", "clusterissuers.cert-manager.io",
+		"kafkatopics.kafka.strimzi.io",
+	}
+	if len(names) != len(expected) {
+		t.Fatalf("expected %q, got %q", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("at index %d: expected %q, got %q", i, expected[i], names[i])
+		}
+	}
+
+	// Without servergroups.json every cached group is loaded
+	resources, err = LoadDiscoveryCache(cacheDir, "https://staging.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resources) != 1 || resources[0].Name != "widgets" {
+		t.Errorf("unexpected resources: %+v", resources)
+	}
+
+	// A missing cache is not an error
+	resources, err = LoadDiscoveryCache(cacheDir, "https://unknown.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resources) != 0 {
+		t.Errorf("expected no resources, got %+v", resources)
+	}
+}
+
+func TestNewDiscoveryResolver(t *testing.T) {
+	resolver := NewDiscoveryResolver(filepath.Join("testdata", "cache"), "https://prod.example.com")
+
+	tests := []struct {
+		input      string
+		expected   string
+		namespaced bool
+	}{
+		{input: "cert", expected: "certificates.cert-manager.io", namespaced: true},
+		{input: "certificate", expected: "certificates.cert-manager.io", namespaced: true},
+		{input: "certificates.cert-manager.io", expected: "certificates.cert-manager.io", namespaced: true},
+		{input: "certificates.v1.cert-manager.io", expected: "certificates.cert-manager.io", namespaced: true},
+		{input: "kt", expected: "kafkatopics.kafka.strimzi.io", namespaced: true},
+		{input: "clusterissuer", expected: "clusterissuers.cert-manager.io"},
+		// the core group wins over events.k8s.io
+		{input: "ev", expected: "events", namespaced: true},
+		{input: "events.events.k8s.io", expected: "events.events.k8s.io", namespaced: true},
+		// not cached, resolved by the built-in table
+		{input: "sts", expected: "statefulsets.apps", namespaced: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			r, ok := resolver.Resolve(tt.input)
+			if !ok {
+				t.Fatalf("expected %q to resolve", tt.input)
+			}
+			if r.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, r.String())
+			}
+			if r.Namespaced != tt.namespaced {
+				t.Errorf("expected namespaced=%v, got %v", tt.namespaced, r.Namespaced)
+			}
+		})
+	}
+
+	if NewDiscoveryResolver(filepath.Join("testdata", "cache"), "https://unknown.example.com") != builtinResolver {
+		t.Error("expected built-in resolver without a cache")
+	}
+}
+
+func TestGuard_Check_DiscoveryCache(t *testing.T) {
+	setupKubeconfig(t)
+
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{
+				Name:       "prod",
+				Namespaces: []string{"payments"},
+				Rules: []config.Rule{
+					{Commands: []string{"delete"}, Resources: []string{"certificates.cert-manager.io"}, Action: config.ActionAllow},
+				},
+			},
+		},
+	}
+	g := New(cfg)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{
+			name:     "short name of a custom resource",
+			args:     []string{"--context", "prod", "-n", "payments", "delete", "cert", "tls"},
+			expected: false,
+		},
+		{
+			name:     "other custom resource",
+			args:     []string{"--context", "prod", "-n", "payments", "delete", "kt", "orders"},
+			expected: true,
+		},
+		{
+			name:     "cluster-scoped custom resource",
+			args:     []string{"--context", "prod", "-n", "web", "delete", "clusterissuer", "letsencrypt"},
+			expected: true,
+		},
+		{
+			name:     "namespaced custom resource in another namespace",
+			args:     []string{"--context", "prod", "-n", "web", "delete", "kt", "orders"},
+			expected: false,
+		},
+		{
+			name:     "cache directory flag",
+			args:     []string{"--context", "prod", "--cache-dir", filepath.Join("testdata", "missing"), "-n", "web", "delete", "kt", "orders"},
+			expected: true,
+		},
+		{
+			name:     "unknown resource may be cluster-scoped",
+			args:     []string{"--context", "prod", "--cache-dir", filepath.Join("testdata", "missing"), "-n", "web", "delete", "clusterissuer", "letsencrypt"},
+			expected: true,
+		},
+		{
+			name:     "unknown resource qualified with its group",
+			args:     []string{"--context", "prod", "--cache-dir", filepath.Join("testdata", "missing"), "-n", "web", "delete", "clusterissuers.cert-manager.io", "letsencrypt"},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Blocked != tt.expected {
+				t.Errorf("expected blocked=%v, got %v", tt.expected, result.Blocked)
+			}
+		})
+	}
+}
@@ -2,218 +2,632 @@
 package guard
 
 import (
+	"errors"
+	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sivchari/kubectl-guard/internal/config"
+	"github.com/sivchari/kubectl-guard/internal/kubeconfig"
 )
 
-// DestructiveCommands lists kubectl commands that modify or delete resources.
-var DestructiveCommands = []string{
-	"delete",
-	"apply",
-	"patch",
-	"replace",
-	"scale",
-	"rollout",
-	"drain",
-	"cordon",
-	"uncordon",
-	"taint",
-	"label",
-	"annotate",
-	"edit",
-	"set",
-}
-
 // Guard provides context protection functionality.
 type Guard struct {
-	cfg *config.Config
+	cfg       *config.Config
+	stdin     []byte
+	kustomize Kustomizer
 }
 
 // New creates a new Guard instance.
 func New(cfg *config.Config) *Guard {
-	return &Guard{cfg: cfg}
+	return &Guard{cfg: cfg, kustomize: KubectlKustomize}
 }
 
+// SetStdin sets the buffered stdin inspected for "-f -" manifests.
+// Without it, objects read from stdin are unknown and always guarded.
+func (g *Guard) SetStdin(data []byte) {
+	g.stdin = data
+}
+
+// SetKustomizer replaces the renderer used for -k kustomizations, KubectlKustomize by default.
+func (g *Guard) SetKustomizer(k Kustomizer) {
+	g.kustomize = k
+}
+
+// Scope represents the part of the cluster a command acts on.
+type Scope string
+
+const (
+	// ScopeNamespace is a command acting on a single namespace.
+	ScopeNamespace Scope = "namespace"
+	// ScopeAllNamespaces is a command acting on every namespace, e.g. with --all-namespaces.
+	ScopeAllNamespaces Scope = "all-namespaces"
+	// ScopeCluster is a command acting on cluster-scoped resources such as nodes or CRDs.
+	ScopeCluster Scope = "cluster"
+)
+
 // CheckResult represents the result of a guard check.
 type CheckResult struct {
-	Blocked   bool
-	Context   string
-	Namespace string
-	Command   string
-	Message   string
+	// Action is the strongest action any targeted object resolves to.
+	Action      config.Action
+	Blocked     bool   // the action is block or deny
+	Denied      bool   // blocked by a deny action, which cannot be overridden
+	Reason      string // why the action applies, e.g. the deciding rule
+	Context     string
+	User        string // kubeconfig user
+	Entry       string // guard entry (context name or pattern) that applies
+	Namespace   string
+	Scope       Scope
+	BlastRadius config.BlastRadius // how many objects the command may reach
+	Command     string
+	Resources   []string  // resource kinds targeted on the command line or in manifests
+	Protected   []string  // protected resources the command would mutate
+	Objects     []Verdict // per-object verdicts on a guarded context
+	Freeze      string    // change freeze in effect, e.g. "Release 2.0 (2026-12-20 09:00 to 2026-12-22 18:00)"
+	// Kustomization is the -k target and Rendered the number of objects rendered from it.
+	Kustomization string
+	Rendered      int
+	Force         bool // kubectl's own --force, e.g. immediate deletion
+	DryRun        bool
+	Message       string
+	// RequireReason and ReasonPattern are the entry's requirements for OverrideReason.
+	RequireReason  bool
+	ReasonPattern  string
+	OverrideReason string // why the guard is overridden, set by SetOverrideReason
+}
+
+// Verdict is the decision for a single targeted object.
+type Verdict struct {
+	Object Object
+	Action config.Action
+	Reason string // e.g. "namespace not guarded" or the deciding rule
+}
+
+// Target represents the kubeconfig, context and namespace a kubectl invocation is aimed at.
+type Target struct {
+	Kubeconfig string
+	Context    string
+	Cluster    string
+	User       string
+	Namespace  string
+	Server     string
+}
+
+// ResolveTarget resolves the effective target of kubectl args the same way kubectl does:
+// --kubeconfig (or KUBECONFIG), --context, --cluster, --user and --namespace override the kubeconfig defaults.
+func ResolveTarget(args []string) (*Target, error) {
+	inv := parseArgs(args)
+	path := inv.value("kubeconfig")
+	kc, err := kubeconfig.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := kc.Resolve(kubeconfig.Overrides{
+		Context:   inv.value("context"),
+		Cluster:   inv.value("cluster"),
+		User:      inv.value("user"),
+		Namespace: inv.value("namespace"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Target{
+		Kubeconfig: path,
+		Context:    resolved.Context,
+		Cluster:    resolved.Cluster,
+		User:       resolved.User,
+		Namespace:  resolved.Namespace,
+		Server:     resolved.Server,
+	}, nil
 }
 
 // Check checks if the command should be blocked.
 func (g *Guard) Check(args []string) (*CheckResult, error) {
-	ctx, err := GetCurrentContext()
+	target, err := ResolveTarget(args)
 	if err != nil {
 		return nil, err
 	}
 
-	ns := GetNamespaceFromArgs(args)
-	if ns == "" {
-		ns, _ = GetCurrentNamespace()
+	inv := parseArgs(args)
+	ctx := target.Context
+	ns := target.Namespace
+	cmd := inv.commandPath()
+
+	cacheDir := inv.value("cache-dir")
+	if cacheDir == "" {
+		cacheDir = DefaultCacheDir()
+	}
+	resolver := NewDiscoveryResolver(cacheDir, target.Server)
+	objects := resolver.objects(inv, ns)
+	if inv.enabled("all-namespaces") {
+		for i := range objects {
+			objects[i].Namespace = ""
+		}
+	}
+
+	now := g.cfg.Now()
+	gc, freeze, guarded, err := g.guards(ctx, inv, now)
+	if err != nil {
+		return nil, err
 	}
 
-	cmd := GetCommand(args)
+	// Manifests are only read, fetched or rendered when their objects may be guarded
+	var manifests []Object
+	if guarded {
+		manifests, err = resolver.manifestObjects(inv, ns, manifestOptions{
+			fetchURLs: g.cfg.FetchManifestURLs,
+			stdin:     g.stdin,
+			kustomize: g.kustomize,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	objects = append(objects, manifests...)
 
 	result := &CheckResult{
-		Context:   ctx,
-		Namespace: ns,
-		Command:   cmd,
+		Action:        config.ActionAllow,
+		Context:       ctx,
+		User:          target.User,
+		Namespace:     ns,
+		Scope:         scopeOf(inv, objects),
+		BlastRadius:   blastRadius(inv, objects),
+		Command:       cmd,
+		Resources:     resourceNames(objects),
+		Kustomization: inv.value("kustomize"),
+		Force:         inv.enabled("force"),
+		DryRun:        inv.dryRun(),
+	}
+	for _, o := range manifests {
+		if o.Kustomized {
+			result.Rendered++
+		}
 	}
 
-	if !g.cfg.IsGuarded(ctx) {
-		return result, nil
+	if gc != nil {
+		result.Entry = gc.String()
+		result.RequireReason = gc.RequireReason
+		result.ReasonPattern = gc.ReasonPattern
 	}
 
-	if !g.cfg.IsNamespaceGuarded(ctx, ns) {
-		return result, nil
+	if guarded && inv.class() == ClassMutate {
+		protected, uninspected := g.protected(ctx, resolver, objects)
+		result.Protected = append(protected, uninspected...)
+		switch {
+		case len(protected) > 0:
+			result.Reason = "protected " + strings.Join(protected, ", ")
+		case len(uninspected) > 0:
+			result.Reason = "protected resources may be in manifests not inspected"
+		}
+		if len(result.Protected) > 0 {
+			result.setAction(config.ActionBlock)
+			return result, nil
+		}
 	}
 
-	if !IsDestructiveCommand(cmd) {
+	if gc == nil && freeze == nil {
 		return result, nil
 	}
 
-	result.Blocked = true
-	result.Message = formatBlockMessage(ctx, ns, cmd)
+	if gc != nil {
+		result.Objects = evaluate(gc, inv, resolver, ns, result.BlastRadius, objects, now)
+	}
+	if freeze != nil {
+		// a freeze guards the whole context, whatever its entry says
+		result.Freeze = freeze.String()
+		frozen := evaluate(&config.GuardedContext{Name: ctx}, inv, resolver, ns, result.BlastRadius, objects, now)
+		for i := range frozen {
+			if frozen[i].Action != config.ActionAllow {
+				frozen[i].Reason = freezeReason + freeze.Summary
+			}
+		}
+		result.Objects = strongestVerdicts(result.Objects, frozen)
+	}
+	action := config.ActionAllow
+	for _, v := range result.Objects {
+		if action.Escalate(v.Action) != action {
+			action = v.Action
+			result.Reason = v.Reason
+		}
+	}
+	result.setAction(action)
 	return result, nil
 }
 
-// GetCurrentContext returns the current kubectl context.
-func GetCurrentContext() (string, error) {
-	cmd := exec.Command("kubectl", "config", "current-context")
-	out, err := cmd.Output()
+// Inspects checks if Check inspects the manifests of the command, i.e. anything may guard it.
+// Stdin only needs to be buffered for "-f -" when it does.
+func (g *Guard) Inspects(args []string) (bool, error) {
+	target, err := ResolveTarget(args)
 	if err != nil {
-		return "", err
+		return false, err
 	}
-	return strings.TrimSpace(string(out)), nil
+	_, _, guarded, err := g.guards(target.Context, parseArgs(args), g.cfg.Now())
+	return guarded, err
 }
 
-// GetCurrentNamespace returns the current namespace from kubeconfig.
-func GetCurrentNamespace() (string, error) {
-	cmd := exec.Command("kubectl", "config", "view", "--minify", "-o", "jsonpath={..namespace}")
-	out, err := cmd.Output()
-	if err != nil {
-		return "default", nil
+// guards returns the entry and the freeze guarding the context at the time, and whether anything
+// may guard the command: either of them, or a protected resource for mutating commands.
+// Nothing is persisted by a dry run, so it is never guarded.
+// A freeze calendar that cannot be read fails mutating commands only, as a freeze blocks nothing else.
+func (g *Guard) guards(ctx string, inv *invocation, now time.Time) (*config.GuardedContext, *config.Freeze, bool, error) {
+	if inv.dryRun() {
+		return nil, nil, false, nil
 	}
-	ns := strings.TrimSpace(string(out))
-	if ns == "" {
-		return "default", nil
+	gc := g.cfg.Lookup(ctx)
+	freeze, err := g.cfg.ActiveFreeze(ctx, now)
+	switch {
+	case err != nil && inv.class() == ClassMutate:
+		return nil, nil, false, err
+	case err != nil:
+		freeze = nil
 	}
-	return ns, nil
+	guarded := gc != nil || freeze != nil || (inv.class() == ClassMutate && g.protects(ctx))
+	return gc, freeze, guarded, nil
 }
 
-// GetNamespaceFromArgs extracts namespace from kubectl args.
-func GetNamespaceFromArgs(args []string) string {
-	for i, arg := range args {
-		if arg == "-n" || arg == "--namespace" {
-			if i+1 < len(args) {
-				return args[i+1]
+// setAction sets the action of the result, the flags derived from it and the message.
+func (r *CheckResult) setAction(action config.Action) {
+	r.Action = action
+	r.Blocked = action == config.ActionBlock || action == config.ActionDeny
+	r.Denied = action == config.ActionDeny
+	if action != config.ActionAllow {
+		r.Message = formatMessage(r)
+	}
+}
+
+// protects checks if any protected resource applies to the context.
+func (g *Guard) protects(ctx string) bool {
+	for i := range g.cfg.ProtectedResources {
+		if g.cfg.ProtectedResources[i].MatchesContext(ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+// protected returns the protected resources among the targeted objects.
+// Namespaced objects without a namespace, e.g. with --all-namespaces, match every namespace,
+// a namespace object touches every namespaced resource protected in it, and the "all" category
+// touches every resource kubectl includes in it.
+// Uninspected manifests may contain any object, so they are returned as uninspected, once per manifest,
+// whenever a protected resource applies to the context.
+func (g *Guard) protected(ctx string, resolver *Resolver, objects []Object) (protected, uninspected []string) {
+	inScope := false
+	for i := range g.cfg.ProtectedResources {
+		p := &g.cfg.ProtectedResources[i]
+		if !p.MatchesContext(ctx) {
+			continue
+		}
+		inScope = true
+		for _, o := range objects {
+			if !o.Uninspected && touches(p, resolver, o) {
+				protected = append(protected, p.String())
+				break
 			}
 		}
-		if strings.HasPrefix(arg, "-n=") {
-			return strings.TrimPrefix(arg, "-n=")
+	}
+	if !inScope {
+		return protected, nil
+	}
+	for _, o := range objects {
+		if o.Uninspected {
+			uninspected = append(uninspected, o.Source+" (not inspected)")
 		}
-		if strings.HasPrefix(arg, "--namespace=") {
-			return strings.TrimPrefix(arg, "--namespace=")
+	}
+	return protected, uninspected
+}
+
+// touches checks if mutating the object may mutate the protected resource.
+func touches(p *config.ProtectedResource, resolver *Resolver, o Object) bool {
+	if isNamespace(o.Resource) && resolver.mayBeNamespaced(p.Kind) && (o.Name == "" || p.MatchesNamespace(o.Name)) {
+		// deleting a namespace deletes everything in it
+		return true
+	}
+	kind := resolver.same(p.Kind, o.Resource) || (o.Resource.Name == allCategory && resolver.inAllCategory(p.Kind))
+	anyNamespace := o.Resource.Namespaced && o.Namespace == ""
+	return kind && p.MatchesName(o.Name) && (anyNamespace || p.MatchesNamespace(o.Namespace))
+}
+
+// evaluate decides every targeted object against the entry at the time.
+// Namespaced objects outside the guarded namespaces are allowed; commands spanning every namespace,
+// cluster-scoped objects and uninspected manifests hit at least one guarded namespace.
+// Objects the entry's schedules do not guard at the time are allowed.
+// Otherwise the first matching rule decides, falling back to isBlockedCommand,
+// mutating commands are escalated by the entry's blast radius policy, and --force blocks.
+func evaluate(gc *config.GuardedContext, inv *invocation, resolver *Resolver, namespace string, radius config.BlastRadius, objects []Object, now time.Time) []Verdict {
+	if len(objects) == 0 {
+		// e.g. "delete --all": only rules without resources can match
+		o := Object{Resource: Resource{Namespaced: true}, Namespace: namespace}
+		if inv.enabled("all-namespaces") {
+			o.Namespace = ""
+		}
+		objects = []Object{o}
+	}
+
+	fallback := config.ActionAllow
+	if isBlockedCommand(gc, inv) {
+		fallback = config.ActionBlock
+	}
+	blast, escalate := gc.BlastRadius[radius]
+	escalate = escalate && inv.class() == ClassMutate
+	// kubectl's own --force blocks the command whatever the rules allow
+	force := inv.enabled("force")
+
+	verdicts := make([]Verdict, 0, len(objects))
+	for _, o := range objects {
+		v := Verdict{Object: o, Action: config.ActionAllow}
+		switch rule := matchRule(gc, resolver, inv.commandPath(), o.Resource); {
+		case !o.Uninspected && !o.Resource.mayBeClusterScoped() && o.Namespace != "" && !gc.IsNamespaceGuarded(o.Namespace):
+			v.Reason = "namespace not guarded"
+			verdicts = append(verdicts, v)
+			continue
+		case !gc.GuardsAt(scheduleNamespace(o), now):
+			v.Reason = "not guarded at this time"
+			verdicts = append(verdicts, v)
+			continue
+		case rule != nil:
+			v.Action = rule.Action
+			v.Reason = "rule " + rule.String()
+		case fallback == config.ActionBlock:
+			v.Action = fallback
+			v.Reason = "blocked command"
+		default:
+			v.Reason = "allowed command"
+		}
+		if escalate && v.Action.Escalate(blast) != v.Action {
+			v.Action = blast
+			v.Reason = "blast radius " + string(radius) + ": " + string(blast)
 		}
+		if force && v.Action.Escalate(config.ActionBlock) != v.Action {
+			v.Action = config.ActionBlock
+			v.Reason = "--force skips graceful deletion and safety checks"
+		}
+		verdicts = append(verdicts, v)
 	}
-	return ""
+	return verdicts
 }
 
-// flagsWithValue lists flags that take a value argument.
-var flagsWithValue = []string{
-	"-n", "--namespace",
-	"-l", "--selector",
-	"-f", "--filename",
-	"-o", "--output",
-	"-c", "--container",
-	"--context",
-	"--kubeconfig",
-	"--cluster",
-	"--user",
+// freezeReason prefixes the reason of verdicts decided by a change freeze.
+const freezeReason = "change freeze "
+
+// strongestVerdicts returns the stronger verdict for every object decided twice.
+// Both decide the same objects in the same order; a nil a takes b as is.
+func strongestVerdicts(a, b []Verdict) []Verdict {
+	if a == nil {
+		return b
+	}
+	for i := range a {
+		if a[i].Action.Escalate(b[i].Action) != a[i].Action {
+			a[i] = b[i]
+		}
+	}
+	return a
 }
 
-// GetCommand extracts the main kubectl command from args.
-func GetCommand(args []string) string {
-	skipNext := false
-	for _, arg := range args {
-		if skipNext {
-			skipNext = false
+// scheduleNamespace returns the namespace the object's schedules are chosen by:
+// its namespace, the namespace itself for namespaces objects, or empty for objects
+// that may be anywhere in the context.
+func scheduleNamespace(o Object) string {
+	switch {
+	case o.Uninspected:
+		return ""
+	case isNamespace(o.Resource):
+		return o.Name
+	case o.Resource.mayBeClusterScoped():
+		return ""
+	}
+	return o.Namespace
+}
+
+// isNamespace checks if the resource is the core namespaces resource.
+func isNamespace(r Resource) bool {
+	return r.Name == "namespaces" && r.Group == ""
+}
+
+// matchRule returns the first rule matching the command and resource.
+func matchRule(gc *config.GuardedContext, resolver *Resolver, path string, resource Resource) *config.Rule {
+	for i := range gc.Rules {
+		rule := &gc.Rules[i]
+		if !rule.MatchesCommand(path) {
 			continue
 		}
-		if strings.HasPrefix(arg, "-") {
-			// Check if this flag takes a value
-			for _, f := range flagsWithValue {
-				if arg == f {
-					skipNext = true
-					break
-				}
+		if len(rule.Resources) == 0 {
+			return rule
+		}
+		for _, entry := range rule.Resources {
+			if resource.Name != "" && resolver.same(entry, resource) {
+				return rule
 			}
-			continue
 		}
-		return arg
 	}
-	return ""
+	return nil
 }
 
-// IsDestructiveCommand checks if the command is destructive.
-func IsDestructiveCommand(cmd string) bool {
-	for _, dc := range DestructiveCommands {
-		if cmd == dc {
-			return true
-		}
+// isBlockedCommand checks the command against the entry's allow and deny lists,
+// falling back to the default taxonomy where only mutating commands are blocked.
+func isBlockedCommand(gc *config.GuardedContext, inv *invocation) bool {
+	if deny, ok := gc.CommandPolicy(inv.commandPath()); ok {
+		return deny
 	}
-	return false
+	return inv.class() == ClassMutate
+}
+
+// GetCurrentContext returns the current kubectl context.
+// An empty kubeconfig means the default kubeconfig (KUBECONFIG or ~/.kube/config).
+func GetCurrentContext(path string) (string, error) {
+	kc, err := kubeconfig.Load(path)
+	if err != nil {
+		return "", err
+	}
+	if kc.CurrentContext == "" {
+		return "", errors.New("current-context is not set")
+	}
+	return kc.CurrentContext, nil
+}
+
+// OverrideFlag overrides the guard for a single invocation. It is never passed to kubectl.
+const OverrideFlag = "--guard-override"
+
+// OverrideEnv overrides the guard when set to a true value such as "1" or "true".
+const OverrideEnv = "KUBECTL_GUARD_OVERRIDE"
+
+// HasOverride checks if the guard override is requested by flag or environment variable.
+func HasOverride(args []string) bool {
+	if v, err := strconv.ParseBool(os.Getenv(OverrideEnv)); err == nil && v {
+		return true
+	}
+	return HasOverrideFlag(args)
 }
 
-// HasForceFlag checks if --force flag is present.
-func HasForceFlag(args []string) bool {
+// HasOverrideFlag checks if --guard-override flag is present before the "--" terminator.
+func HasOverrideFlag(args []string) bool {
 	for _, arg := range args {
-		if arg == "--force" {
+		if arg == "--" {
+			return false
+		}
+		if arg == OverrideFlag {
 			return true
 		}
 	}
 	return false
 }
 
-// RemoveForceFlag removes --force flag from args.
-func RemoveForceFlag(args []string) []string {
+// RemoveOverrideFlag removes --guard-override flag from args.
+// Args after the "--" terminator belong to another program and are kept as is.
+func RemoveOverrideFlag(args []string) []string {
 	result := make([]string, 0, len(args))
-	for _, arg := range args {
-		if arg != "--force" {
+	for i, arg := range args {
+		if arg == "--" {
+			return append(result, args[i:]...)
+		}
+		if arg != OverrideFlag {
 			result = append(result, arg)
 		}
 	}
 	return result
 }
 
-// ExecKubectl executes kubectl with the given args.
-func ExecKubectl(args []string) error {
+// ExecKubectl executes kubectl with the given args and returns its exit code.
+// A non-nil stdin replaces os.Stdin, e.g. to replay a buffered "-f -" manifest.
+// The error is only set when kubectl could not be run at all.
+func ExecKubectl(args []string, stdin io.Reader) (int, error) {
 	kubectlPath, err := exec.LookPath("kubectl")
 	if err != nil {
-		return err
+		return -1, err
 	}
-	return execCommand(kubectlPath, args)
+	return execCommand(kubectlPath, args, stdin)
 }
 
-func execCommand(path string, args []string) error {
+func execCommand(path string, args []string, stdin io.Reader) (int, error) {
+	if stdin == nil {
+		stdin = os.Stdin
+	}
 	cmd := exec.Command(path, args...)
-	cmd.Stdin = os.Stdin
+	cmd.Stdin = stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return -1, err
+	}
+	return 0, nil
+}
+
+func formatNamespace(result *CheckResult) string {
+	switch result.Scope {
+	case ScopeAllNamespaces:
+		return "(all namespaces)"
+	case ScopeCluster:
+		return "(cluster-scoped)"
+	case ScopeNamespace:
+		return result.Namespace
+	}
+	return result.Namespace
 }
 
-func formatBlockMessage(ctx, ns, cmd string) string {
-	return "blocked\n" +
-		"  context: " + ctx + "\n" +
-		"  namespace: " + ns + "\n" +
-		"  command: " + cmd + "\n\n" +
+// formatMessage describes the result, headed by what happens to the command.
+func formatMessage(result *CheckResult) string {
+	var msg string
+	switch result.Action {
+	case config.ActionWarn:
+		msg = "warning\n"
+	case config.ActionConfirm:
+		msg = "confirmation required\n"
+	case config.ActionAllow, config.ActionBlock, config.ActionDeny:
+		msg = "blocked\n"
+	}
+	msg +=
+		"  context: " + result.Context + "\n" +
+			"  namespace: " + formatNamespace(result) + "\n" +
+			"  command: " + result.Command + "\n"
+	if len(result.Resources) > 0 {
+		msg += "  resources: " + strings.Join(result.Resources, ", ") + "\n"
+	}
+	if result.BlastRadius != "" && result.BlastRadius != config.BlastRadiusNamed {
+		msg += "  blast radius: " + string(result.BlastRadius) + "\n"
+	}
+	if target := result.Kustomization; target != "" {
+		msg += "  kustomization: " + target + " (" + strconv.Itoa(result.Rendered) + " objects rendered)\n"
+	}
+	for _, p := range result.Protected {
+		msg += "  protected: " + p + "\n"
+	}
+	if result.Freeze != "" {
+		msg += "  change freeze: " + result.Freeze + "\n"
+	}
+	for _, v := range result.Objects {
+		if v.Action == config.ActionAllow || (v.Object.Resource.Name == "" && !v.Object.Uninspected) {
+			// allowed, or the command itself, e.g. "delete --all"
+			continue
+		}
+		msg += "  object: " + v.Object.String()
+		if v.Object.Source != "" {
+			msg += " from " + v.Object.Source
+		}
+		msg += " (" + v.Reason + ")\n"
+	}
+	if result.Force {
+		msg += "  force: --force skips graceful deletion and safety checks\n"
+	}
+	if result.RequireReason && (result.Action == config.ActionConfirm || result.Action == config.ActionBlock) {
+		msg += "  reason: required to override, add " + ReasonFlag + "=<reason>"
+		if result.ReasonPattern != "" {
+			msg += " matching " + result.ReasonPattern
+		}
+		msg += "\n"
+	}
+	switch result.Action {
+	case config.ActionWarn:
+		return msg + "\n" +
+			"This context is guarded."
+	case config.ActionConfirm:
+		return msg + "\n" +
+			"This context is guarded and the command must be confirmed.\n" +
+			"Use " + OverrideFlag + " flag (or " + OverrideEnv + "=1) to execute without confirmation."
+	case config.ActionDeny:
+		return msg + "\n" +
+			"This command is denied on this context and cannot be overridden."
+	case config.ActionAllow, config.ActionBlock:
+	}
+	if strings.HasPrefix(result.Reason, freezeReason) {
+		return msg + "\n" +
+			"A change freeze is in effect on this context.\n" +
+			"Use " + OverrideFlag + " flag (or " + OverrideEnv + "=1) to execute anyway."
+	}
+	if len(result.Protected) > 0 {
+		return msg + "\n" +
+			"These resources are protected.\n" +
+			"Use " + OverrideFlag + " flag (or " + OverrideEnv + "=1) to execute, or remove them from protectedResources in the config."
+	}
+	return msg + "\n" +
 		"This context is guarded.\n" +
-		"Use --force flag to execute, or run `kubectl guard unguard " + ctx + "` to remove protection."
+		"Use " + OverrideFlag + " flag (or " + OverrideEnv + "=1) to execute, or run `kubectl guard unguard " + result.Entry + "` to remove protection."
 }
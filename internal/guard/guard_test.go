@@ -1,200 +1,991 @@
 package guard
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sivchari/kubectl-guard/internal/config"
 )
 
-func TestGetNamespaceFromArgs(t *testing.T) {
+func TestClassifyCommand(t *testing.T) {
+	destructive := []string{
+		"delete", "apply", "patch", "replace", "scale",
+		"rollout", "drain", "cordon", "uncordon", "taint",
+		"label", "annotate", "edit", "set",
+	}
+	for _, cmd := range destructive {
+		if ClassifyCommand(cmd) != ClassMutate {
+			t.Errorf("expected %q to be destructive", cmd)
+		}
+	}
+
+	safe := []string{"get", "describe", "logs", "exec", "port-forward", "top"}
+	for _, cmd := range safe {
+		if ClassifyCommand(cmd) == ClassMutate {
+			t.Errorf("expected %q to be safe", cmd)
+		}
+	}
+}
+
+func TestHasOverrideFlag(t *testing.T) {
 	tests := []struct {
 		name     string
 		args     []string
-		expected string
+		expected bool
 	}{
 		{
-			name:     "short flag with space",
-			args:     []string{"delete", "pod", "-n", "production", "nginx"},
-			expected: "production",
+			name:     "with override flag",
+			args:     []string{"delete", "--guard-override", "pod", "nginx"},
+			expected: true,
+		},
+		{
+			name:     "without override flag",
+			args:     []string{"delete", "pod", "nginx"},
+			expected: false,
+		},
+		{
+			name:     "kubectl force flag is not an override",
+			args:     []string{"delete", "pod", "nginx", "--force"},
+			expected: false,
 		},
 		{
-			name:     "short flag with equals",
-			args:     []string{"delete", "pod", "-n=production", "nginx"},
-			expected: "production",
+			name:     "override flag after terminator",
+			args:     []string{"exec", "nginx", "--", "cmd", "--guard-override"},
+			expected: false,
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasOverrideFlag(tt.args)
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHasOverride_Env(t *testing.T) {
+	t.Setenv(OverrideEnv, "1")
+	if !HasOverride([]string{"delete", "pod", "nginx"}) {
+		t.Error("expected override from environment")
+	}
+
+	t.Setenv(OverrideEnv, "false")
+	if HasOverride([]string{"delete", "pod", "nginx"}) {
+		t.Error("expected no override from false environment value")
+	}
+}
+
+func TestRemoveOverrideFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
 		{
-			name:     "long flag with space",
-			args:     []string{"delete", "pod", "--namespace", "production", "nginx"},
-			expected: "production",
+			name:     "with override flag",
+			args:     []string{"delete", "--guard-override", "pod", "nginx"},
+			expected: []string{"delete", "pod", "nginx"},
 		},
 		{
-			name:     "long flag with equals",
-			args:     []string{"delete", "pod", "--namespace=production", "nginx"},
-			expected: "production",
+			name:     "kubectl force flag is kept",
+			args:     []string{"delete", "pod", "nginx", "--force", "--grace-period=0"},
+			expected: []string{"delete", "pod", "nginx", "--force", "--grace-period=0"},
 		},
 		{
-			name:     "no namespace flag",
-			args:     []string{"delete", "pod", "nginx"},
-			expected: "",
+			name:     "multiple override flags",
+			args:     []string{"--guard-override", "delete", "--guard-override", "pod"},
+			expected: []string{"delete", "pod"},
 		},
 		{
-			name:     "flag at end without value",
-			args:     []string{"delete", "pod", "-n"},
-			expected: "",
+			name:     "args after terminator are kept",
+			args:     []string{"--guard-override", "exec", "nginx", "--", "cmd", "--guard-override"},
+			expected: []string{"exec", "nginx", "--", "cmd", "--guard-override"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := GetNamespaceFromArgs(tt.args)
-			if result != tt.expected {
-				t.Errorf("expected %q, got %q", tt.expected, result)
+			result := RemoveOverrideFlag(tt.args)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected length %d, got %d", len(tt.expected), len(result))
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("at index %d: expected %q, got %q", i, tt.expected[i], result[i])
+				}
 			}
 		})
 	}
 }
 
-func TestGetCommand(t *testing.T) {
+// setupKubeconfig points kubectl's kubeconfig and cache directory at the test fixtures.
+func setupKubeconfig(t *testing.T) {
+	t.Helper()
+	t.Setenv("KUBECONFIG", filepath.Join("testdata", "kubeconfig.yaml"))
+	t.Setenv(CacheDirEnv, filepath.Join("testdata", "cache"))
+}
+
+func TestResolveTarget(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join("testdata", "kubeconfig.yaml"))
+
 	tests := []struct {
-		name     string
-		args     []string
-		expected string
+		name              string
+		args              []string
+		expectedContext   string
+		expectedNamespace string
 	}{
 		{
-			name:     "simple command",
-			args:     []string{"delete", "pod", "nginx"},
-			expected: "delete",
+			name:              "current context",
+			args:              []string{"delete", "pod", "nginx"},
+			expectedContext:   "dev",
+			expectedNamespace: "default",
 		},
 		{
-			name:     "with flags first",
-			args:     []string{"-n", "production", "delete", "pod", "nginx"},
-			expected: "delete",
+			name:              "context flag",
+			args:              []string{"--context", "prod", "delete", "ns", "payments"},
+			expectedContext:   "prod",
+			expectedNamespace: "default",
 		},
 		{
-			name:     "only flags",
-			args:     []string{"-n", "production"},
-			expected: "",
+			name:              "context default namespace",
+			args:              []string{"--context=staging", "delete", "pod", "nginx"},
+			expectedContext:   "staging",
+			expectedNamespace: "critical",
 		},
 		{
-			name:     "empty args",
-			args:     []string{},
-			expected: "",
+			name:              "namespace flag overrides context namespace",
+			args:              []string{"--context=staging", "-n", "web", "delete", "pod", "nginx"},
+			expectedContext:   "staging",
+			expectedNamespace: "web",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := GetCommand(tt.args)
-			if result != tt.expected {
-				t.Errorf("expected %q, got %q", tt.expected, result)
+			target, err := ResolveTarget(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if target.Context != tt.expectedContext {
+				t.Errorf("expected context %q, got %q", tt.expectedContext, target.Context)
+			}
+			if target.Namespace != tt.expectedNamespace {
+				t.Errorf("expected namespace %q, got %q", tt.expectedNamespace, target.Namespace)
 			}
 		})
 	}
 }
 
-func TestIsDestructiveCommand(t *testing.T) {
-	destructive := []string{
-		"delete", "apply", "patch", "replace", "scale",
-		"rollout", "drain", "cordon", "uncordon", "taint",
-		"label", "annotate", "edit", "set",
+func TestResolveTarget_KubeconfigFlag(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join("testdata", "missing.yaml"))
+
+	target, err := ResolveTarget([]string{"--kubeconfig", filepath.Join("testdata", "kubeconfig.yaml"), "get", "pods"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	for _, cmd := range destructive {
-		if !IsDestructiveCommand(cmd) {
-			t.Errorf("expected %q to be destructive", cmd)
-		}
+	if target.Context != "dev" {
+		t.Errorf("expected context %q, got %q", "dev", target.Context)
 	}
 
-	safe := []string{"get", "describe", "logs", "exec", "port-forward", "top"}
-	for _, cmd := range safe {
-		if IsDestructiveCommand(cmd) {
-			t.Errorf("expected %q to be safe", cmd)
-		}
+	if _, err := ResolveTarget([]string{"--context", "missing", "get", "pods"}); err == nil {
+		t.Error("expected error for unknown context")
 	}
 }
 
-func TestHasForceFlag(t *testing.T) {
+func TestGuard_Check(t *testing.T) {
+	setupKubeconfig(t)
+
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{Name: "prod"},
+			{Name: "staging", Namespaces: []string{"critical"}},
+		},
+	}
+	g := New(cfg)
+
 	tests := []struct {
 		name     string
 		args     []string
 		expected bool
+		force    bool
+		dryRun   bool
 	}{
 		{
-			name:     "with force flag",
-			args:     []string{"delete", "--force", "pod", "nginx"},
+			name:     "unguarded current context",
+			args:     []string{"delete", "ns", "payments"},
+			expected: false,
+		},
+		{
+			name:     "guarded context via flag",
+			args:     []string{"--context", "prod", "delete", "ns", "payments"},
 			expected: true,
 		},
 		{
-			name:     "without force flag",
-			args:     []string{"delete", "pod", "nginx"},
+			name:     "read command on guarded context",
+			args:     []string{"--context", "prod", "get", "pods"},
 			expected: false,
 		},
 		{
-			name:     "force flag at end",
-			args:     []string{"delete", "pod", "nginx", "--force"},
+			name:     "read-only subcommand on guarded context",
+			args:     []string{"--context", "prod", "rollout", "status", "deploy/web"},
+			expected: false,
+		},
+		{
+			name:     "mutating subcommand on guarded context",
+			args:     []string{"--context", "prod", "rollout", "restart", "deploy/web"},
+			expected: true,
+		},
+		{
+			name:     "debug on guarded context",
+			args:     []string{"--context", "prod", "debug", "node/worker-1", "-it", "--image", "busybox"},
+			expected: true,
+		},
+		{
+			name:     "unknown command on guarded context",
+			args:     []string{"--context", "prod", "some-plugin", "sync"},
+			expected: true,
+		},
+		{
+			name:     "guarded namespace from context default",
+			args:     []string{"--context", "staging", "delete", "pod", "nginx"},
+			expected: true,
+		},
+		{
+			name:     "unguarded namespace",
+			args:     []string{"--context", "staging", "-n", "web", "delete", "pod", "nginx"},
+			expected: false,
+		},
+		{
+			name:     "all namespaces on namespace-limited context",
+			args:     []string{"--context", "staging", "-n", "web", "delete", "pods", "--all", "-A"},
+			expected: true,
+		},
+		{
+			name:     "all namespaces read on guarded context",
+			args:     []string{"--context", "staging", "get", "pods", "-A"},
+			expected: false,
+		},
+		{
+			name:     "drain on namespace-limited context",
+			args:     []string{"--context", "staging", "-n", "web", "drain", "node-1"},
+			expected: true,
+		},
+		{
+			name:     "cluster-scoped kind on namespace-limited context",
+			args:     []string{"--context", "staging", "-n", "web", "delete", "crd", "widgets.example.com"},
+			expected: true,
+		},
+		{
+			name:     "cluster-scoped kind in type/name form",
+			args:     []string{"--context", "staging", "-n", "web", "delete", "clusterrole/admin"},
+			expected: true,
+		},
+		{
+			name:     "cluster-scoped kind on unguarded context",
+			args:     []string{"delete", "pv", "data"},
+			expected: false,
+		},
+		{
+			name:     "kubectl force flag on guarded context",
+			args:     []string{"--context", "prod", "delete", "pod", "nginx", "--force", "--grace-period=0"},
+			expected: true,
+			force:    true,
+		},
+		{
+			name:     "server dry run on guarded context",
+			args:     []string{"--context", "prod", "apply", "-f", "testdata/manifests/deploy.yaml", "--dry-run=server"},
+			expected: false,
+			dryRun:   true,
+		},
+		{
+			name:     "client dry run on guarded context",
+			args:     []string{"--context", "prod", "delete", "pod", "x", "--dry-run=client"},
+			expected: false,
+			dryRun:   true,
+		},
+		{
+			name:     "dry run none on guarded context",
+			args:     []string{"--context", "prod", "delete", "pod", "x", "--dry-run=none"},
 			expected: true,
 		},
+		{
+			name:     "kubectl force flag on unguarded context",
+			args:     []string{"delete", "pod", "nginx", "--force"},
+			expected: false,
+			force:    true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := HasForceFlag(tt.args)
-			if result != tt.expected {
-				t.Errorf("expected %v, got %v", tt.expected, result)
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Blocked != tt.expected {
+				t.Errorf("expected blocked=%v, got %v", tt.expected, result.Blocked)
+			}
+			if result.Force != tt.force {
+				t.Errorf("expected force=%v, got %v", tt.force, result.Force)
+			}
+			if result.DryRun != tt.dryRun {
+				t.Errorf("expected dryRun=%v, got %v", tt.dryRun, result.DryRun)
 			}
 		})
 	}
 }
 
-func TestRemoveForceFlag(t *testing.T) {
+func TestGuard_Check_CommandPolicy(t *testing.T) {
+	setupKubeconfig(t)
+
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{Name: "prod", AllowCommands: []string{"scale", "rollout restart"}},
+			{Name: "staging", DenyCommands: []string{"exec", "port-forward"}},
+		},
+	}
+	g := New(cfg)
+
 	tests := []struct {
 		name     string
 		args     []string
-		expected []string
+		expected bool
 	}{
 		{
-			name:     "with force flag",
-			args:     []string{"delete", "--force", "pod", "nginx"},
-			expected: []string{"delete", "pod", "nginx"},
+			name:     "allowed command",
+			args:     []string{"--context", "prod", "scale", "deploy/web", "--replicas=3"},
+			expected: false,
 		},
 		{
-			name:     "without force flag",
-			args:     []string{"delete", "pod", "nginx"},
-			expected: []string{"delete", "pod", "nginx"},
+			name:     "allowed subcommand",
+			args:     []string{"--context", "prod", "rollout", "restart", "deploy/web"},
+			expected: false,
 		},
 		{
-			name:     "multiple force flags",
-			args:     []string{"--force", "delete", "--force", "pod"},
-			expected: []string{"delete", "pod"},
+			name:     "other subcommand still blocked",
+			args:     []string{"--context", "prod", "rollout", "undo", "deploy/web"},
+			expected: true,
+		},
+		{
+			name:     "default taxonomy still applies",
+			args:     []string{"--context", "prod", "delete", "pod", "nginx"},
+			expected: true,
+		},
+		{
+			name:     "denied access command",
+			args:     []string{"--context", "staging", "exec", "-it", "nginx", "--", "sh"},
+			expected: true,
+		},
+		{
+			name:     "denied command in another namespace",
+			args:     []string{"--context", "staging", "-n", "web", "port-forward", "svc/web", "8080:80"},
+			expected: true,
+		},
+		{
+			name:     "read command",
+			args:     []string{"--context", "staging", "logs", "nginx"},
+			expected: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := RemoveForceFlag(tt.args)
-			if len(result) != len(tt.expected) {
-				t.Fatalf("expected length %d, got %d", len(tt.expected), len(result))
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
 			}
-			for i := range result {
-				if result[i] != tt.expected[i] {
-					t.Errorf("at index %d: expected %q, got %q", i, tt.expected[i], result[i])
-				}
+			if result.Blocked != tt.expected {
+				t.Errorf("expected blocked=%v, got %v", tt.expected, result.Blocked)
 			}
 		})
 	}
 }
 
-func TestGuard_Check(t *testing.T) {
+func TestGuard_Check_Rules(t *testing.T) {
+	setupKubeconfig(t)
+
 	cfg := &config.Config{
 		GuardedContexts: []config.GuardedContext{
-			{Name: "prod"},
-			{Name: "staging", Namespaces: []string{"critical"}},
+			{
+				Name: "prod",
+				Rules: []config.Rule{
+					{Commands: []string{"delete"}, Resources: []string{"po"}, Action: config.ActionAllow},
+					{Commands: []string{"get"}, Resources: []string{"secrets"}, Action: config.ActionBlock},
+					{Commands: []string{"rollout restart"}, Action: config.ActionAllow},
+				},
+			},
 		},
 	}
 	g := New(cfg)
 
-	// Note: These tests would require mocking kubectl commands
-	// For now, we just verify the Guard struct is properly initialized
-	if g.cfg != cfg {
-		t.Error("Guard config not properly set")
+	tests := []struct {
+		name      string
+		args      []string
+		expected  bool
+		resources []string
+	}{
+		{
+			name:      "allowed kind",
+			args:      []string{"--context", "prod", "delete", "pod", "stuck-pod"},
+			expected:  false,
+			resources: []string{"pods"},
+		},
+		{
+			name:      "allowed kind in type/name form",
+			args:      []string{"--context", "prod", "delete", "pods/a", "po/b"},
+			expected:  false,
+			resources: []string{"pods"},
+		},
+		{
+			name:      "dangerous kind",
+			args:      []string{"--context", "prod", "delete", "namespace", "payments"},
+			expected:  true,
+			resources: []string{"namespaces"},
+		},
+		{
+			name:      "mixed kinds are blocked if any kind is",
+			args:      []string{"--context", "prod", "delete", "po,deploy", "web"},
+			expected:  true,
+			resources: []string{"pods", "deployments.apps"},
+		},
+		{
+			name:      "rule blocks a read command",
+			args:      []string{"--context", "prod", "get", "secret", "db-password", "-o", "yaml"},
+			expected:  true,
+			resources: []string{"secrets"},
+		},
+		{
+			name:      "command rule without resources",
+			args:      []string{"--context", "prod", "rollout", "restart", "deploy/web"},
+			expected:  false,
+			resources: []string{"deployments.apps"},
+		},
+		{
+			name:      "resources from a manifest",
+			args:      []string{"--context", "prod", "apply", "-f", "testdata/manifests/deploy.yaml"},
+			expected:  true,
+			resources: []string{"deployments.apps"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Blocked != tt.expected {
+				t.Errorf("expected blocked=%v, got %v", tt.expected, result.Blocked)
+			}
+			if !slices.Equal(result.Resources, tt.resources) {
+				t.Errorf("expected resources %q, got %q", tt.resources, result.Resources)
+			}
+		})
+	}
+}
+
+func TestGuard_Check_ProtectedResources(t *testing.T) {
+	setupKubeconfig(t)
+
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{Name: "prod", AllowCommands: []string{"delete"}},
+		},
+		ProtectedResources: []config.ProtectedResource{
+			{Context: "prod", Namespace: "payments", Kind: "secret", Name: "db-*"},
+			{Kind: "ns", Name: "kube-system"},
+		},
+	}
+	g := New(cfg)
+
+	tests := []struct {
+		name      string
+		args      []string
+		protected []string
+	}{
+		{
+			name:      "named on the command line",
+			args:      []string{"--context", "prod", "-n", "payments", "delete", "secret", "db-password"},
+			protected: []string{"secret/db-* in prod/payments"},
+		},
+		{
+			name:      "type/name form with a kind alias",
+			args:      []string{"--context", "dev", "delete", "namespaces/kube-system"},
+			protected: []string{"ns/kube-system"},
+		},
+		{
+			name: "other name",
+			args: []string{"--context", "prod", "-n", "payments", "delete", "secret", "api-token"},
+		},
+		{
+			name: "other namespace",
+			args: []string{"--context", "prod", "-n", "default", "delete", "secret", "db-password"},
+		},
+		{
+			name: "other context",
+			args: []string{"--context", "staging", "-n", "payments", "delete", "secret", "db-password"},
+		},
+		{
+			name:      "selector may match a protected name",
+			args:      []string{"--context", "prod", "-n", "payments", "delete", "secrets", "-l", "app=db"},
+			protected: []string{"secret/db-* in prod/payments"},
+		},
+		{
+			name:      "all namespaces",
+			args:      []string{"--context", "prod", "delete", "secret", "db-password", "-A"},
+			protected: []string{"secret/db-* in prod/payments"},
+		},
+		{
+			name:      "named in a manifest",
+			args:      []string{"--context", "prod", "apply", "-f", "testdata/manifests/secrets.yaml"},
+			protected: []string{"secret/db-* in prod/payments"},
+		},
+		{
+			name:      "created with a subtype",
+			args:      []string{"--context", "prod", "-n", "payments", "create", "secret", "generic", "db-password"},
+			protected: []string{"secret/db-* in prod/payments"},
+		},
+		{
+			name:      "namespace holding a protected resource",
+			args:      []string{"--context", "prod", "delete", "ns", "payments"},
+			protected: []string{"secret/db-* in prod/payments"},
+		},
+		{
+			name: "namespace in another context",
+			args: []string{"--context", "dev", "delete", "ns", "payments"},
+		},
+		{
+			name:      "uninspected manifest on a guarded context",
+			args:      []string{"--context", "prod", "apply", "-f", "https://example.com/app.yaml"},
+			protected: []string{"https://example.com/app.yaml (not inspected)"},
+		},
+		{
+			name:      "uninspected manifest on an unguarded context",
+			args:      []string{"--context", "dev", "apply", "-f", "https://example.com/app.yaml"},
+			protected: []string{"https://example.com/app.yaml (not inspected)"},
+		},
+		{
+			name: "read command",
+			args: []string{"--context", "prod", "-n", "payments", "get", "secret", "db-password"},
+		},
+		{
+			name: "dry run",
+			args: []string{"--context", "prod", "-n", "payments", "delete", "secret", "db-password", "--dry-run=server"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(result.Protected, tt.protected) {
+				t.Errorf("expected protected %q, got %q", tt.protected, result.Protected)
+			}
+			if result.Blocked != (len(tt.protected) > 0) {
+				t.Errorf("expected blocked=%v, got %v", len(tt.protected) > 0, result.Blocked)
+			}
+		})
+	}
+}
+
+func TestGuard_Check_ProtectedResourcesInAnyNamespace(t *testing.T) {
+	setupKubeconfig(t)
+
+	g := New(&config.Config{
+		ProtectedResources: []config.ProtectedResource{
+			{Kind: "statefulset", Name: "postgres"},
+			{Kind: "node", Name: "control-plane"},
+		},
+	})
+
+	tests := []struct {
+		name      string
+		args      []string
+		protected []string
+	}{
+		{
+			name:      "namespace deletion",
+			args:      []string{"--context", "dev", "delete", "ns", "db"},
+			protected: []string{"statefulset/postgres"},
+		},
+		{
+			name:      "all category",
+			args:      []string{"--context", "dev", "-n", "db", "delete", "all", "--all"},
+			protected: []string{"statefulset/postgres"},
+		},
+		{
+			name:      "all category with a selector",
+			args:      []string{"--context", "dev", "-n", "db", "delete", "all", "-l", "app=db"},
+			protected: []string{"statefulset/postgres"},
+		},
+		{
+			name: "all category by name",
+			args: []string{"--context", "dev", "-n", "db", "delete", "all", "web"},
+		},
+		{
+			name: "other kind",
+			args: []string{"--context", "dev", "-n", "db", "delete", "deploy", "--all"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(result.Protected, tt.protected) {
+				t.Errorf("expected protected %q, got %q", tt.protected, result.Protected)
+			}
+		})
+	}
+}
+
+func TestGuard_Check_Severity(t *testing.T) {
+	setupKubeconfig(t)
+
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{
+				Name: "prod",
+				Rules: []config.Rule{
+					{Commands: []string{"scale"}, Action: config.ActionWarn},
+					{Commands: []string{"rollout restart"}, Action: config.ActionConfirm},
+					{Commands: []string{"delete"}, Resources: []string{"ns"}, Action: config.ActionDeny},
+				},
+				BlastRadius: map[config.BlastRadius]config.Action{
+					config.BlastRadiusAll: config.ActionConfirm,
+				},
+			},
+		},
+	}
+	g := New(cfg)
+
+	tests := []struct {
+		name    string
+		args    []string
+		action  config.Action
+		blocked bool
+		header  string
+	}{
+		{
+			name:   "read command",
+			args:   []string{"--context", "prod", "get", "pods"},
+			action: config.ActionAllow,
+		},
+		{
+			name:   "warn",
+			args:   []string{"--context", "prod", "scale", "deploy/web", "--replicas=3"},
+			action: config.ActionWarn,
+			header: "warning\n",
+		},
+		{
+			name:   "confirm",
+			args:   []string{"--context", "prod", "rollout", "restart", "deploy/web"},
+			action: config.ActionConfirm,
+			header: "confirmation required\n",
+		},
+		{
+			name:   "blast radius escalates warn to confirm",
+			args:   []string{"--context", "prod", "scale", "deploy", "--all", "--replicas=0"},
+			action: config.ActionConfirm,
+			header: "confirmation required\n",
+		},
+		{
+			name:    "block",
+			args:    []string{"--context", "prod", "delete", "pod", "nginx"},
+			action:  config.ActionBlock,
+			blocked: true,
+			header:  "blocked\n",
+		},
+		{
+			name:    "deny",
+			args:    []string{"--context", "prod", "delete", "ns", "payments"},
+			action:  config.ActionDeny,
+			blocked: true,
+			header:  "blocked\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Action != tt.action {
+				t.Errorf("expected action %s, got %s", tt.action, result.Action)
+			}
+			if result.Blocked != tt.blocked {
+				t.Errorf("expected blocked=%v, got %v", tt.blocked, result.Blocked)
+			}
+			if (tt.action == config.ActionAllow) != (result.Reason == "") {
+				t.Errorf("unexpected reason %q for action %s", result.Reason, result.Action)
+			}
+			if !strings.HasPrefix(result.Message, tt.header) || (tt.header == "") != (result.Message == "") {
+				t.Errorf("expected message starting with %q, got %q", tt.header, result.Message)
+			}
+		})
+	}
+}
+
+func TestGuard_Check_Schedules(t *testing.T) {
+	setupKubeconfig(t)
+
+	var now time.Time
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{
+				Name: "prod",
+				Schedules: []config.Schedule{
+					// weekend change freeze
+					{Timezone: "UTC", Windows: []config.Window{{Cron: "0 18 * * fri", Duration: 62 * time.Hour}}},
+					// payments is also guarded outside business hours
+					{
+						Guard:      config.ScheduleOutside,
+						Timezone:   "UTC",
+						Namespaces: []string{"payments"},
+						Windows:    []config.Window{{Cron: "0 9 * * mon-fri", Duration: 8 * time.Hour}},
+					},
+				},
+			},
+		},
+		Clock: func() time.Time { return now },
+	}
+	g := New(cfg)
+
+	// 2026-01-02 is a Friday
+	tests := []struct {
+		name    string
+		now     time.Time
+		args    []string
+		blocked bool
+	}{
+		{
+			name:    "weekend freeze",
+			now:     time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC),
+			args:    []string{"--context", "prod", "delete", "pod", "nginx"},
+			blocked: true,
+		},
+		{
+			name: "weekday",
+			now:  time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC),
+			args: []string{"--context", "prod", "delete", "pod", "nginx"},
+		},
+		{
+			name:    "payments after hours",
+			now:     time.Date(2026, 1, 7, 20, 0, 0, 0, time.UTC),
+			args:    []string{"--context", "prod", "-n", "payments", "delete", "pod", "nginx"},
+			blocked: true,
+		},
+		{
+			name: "other namespace after hours",
+			now:  time.Date(2026, 1, 7, 20, 0, 0, 0, time.UTC),
+			args: []string{"--context", "prod", "-n", "default", "delete", "pod", "nginx"},
+		},
+		{
+			name:    "all namespaces after hours",
+			now:     time.Date(2026, 1, 7, 20, 0, 0, 0, time.UTC),
+			args:    []string{"--context", "prod", "delete", "pods", "--all", "-A"},
+			blocked: true,
+		},
+		{
+			name:    "payments namespace after hours",
+			now:     time.Date(2026, 1, 7, 20, 0, 0, 0, time.UTC),
+			args:    []string{"--context", "prod", "delete", "ns", "payments"},
+			blocked: true,
+		},
+		{
+			name: "other namespace object after hours",
+			now:  time.Date(2026, 1, 7, 20, 0, 0, 0, time.UTC),
+			args: []string{"--context", "prod", "delete", "ns", "sandbox"},
+		},
+		{
+			name: "payments in business hours",
+			now:  time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC),
+			args: []string{"--context", "prod", "-n", "payments", "delete", "pod", "nginx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = tt.now
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Blocked != tt.blocked {
+				t.Errorf("expected blocked=%v, got %v (%s)", tt.blocked, result.Blocked, result.Reason)
+			}
+		})
+	}
+}
+
+func TestGuard_Check_Freeze(t *testing.T) {
+	setupKubeconfig(t)
+
+	calendar := filepath.Join(t.TempDir(), "release.ics")
+	content := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:Release 2.0\nCATEGORIES:FREEZE\n" +
+		"DTSTART:20261220T090000Z\nDTEND:20261222T180000Z\nEND:VEVENT\nEND:VCALENDAR\n"
+	if err := os.WriteFile(calendar, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var now time.Time
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{Name: "prod", Namespaces: []string{"payments"}, AllowCommands: []string{"scale"}},
+		},
+		FreezeCalendars: []config.FreezeCalendar{
+			{File: calendar, Contexts: []string{"prod", "dev"}, Categories: []string{"freeze"}},
+		},
+		Clock: func() time.Time { return now },
+	}
+	g := New(cfg)
+
+	frozen := time.Date(2026, 12, 21, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		now     time.Time
+		args    []string
+		blocked bool
+	}{
+		{
+			name:    "unguarded context",
+			now:     frozen,
+			args:    []string{"--context", "dev", "delete", "pod", "nginx"},
+			blocked: true,
+		},
+		{
+			name:    "unguarded namespace",
+			now:     frozen,
+			args:    []string{"--context", "prod", "-n", "default", "delete", "pod", "nginx"},
+			blocked: true,
+		},
+		{
+			name:    "allowed command",
+			now:     frozen,
+			args:    []string{"--context", "prod", "-n", "payments", "scale", "deploy/web", "--replicas=3"},
+			blocked: true,
+		},
+		{
+			name: "read command",
+			now:  frozen,
+			args: []string{"--context", "dev", "get", "pods"},
+		},
+		{
+			name: "after the freeze",
+			now:  time.Date(2026, 12, 23, 12, 0, 0, 0, time.UTC),
+			args: []string{"--context", "dev", "delete", "pod", "nginx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = tt.now
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Blocked != tt.blocked {
+				t.Errorf("expected blocked=%v, got %v (%s)", tt.blocked, result.Blocked, result.Reason)
+			}
+			if tt.blocked && (result.Reason != "change freeze Release 2.0" || !strings.Contains(result.Message, "change freeze: Release 2.0")) {
+				t.Errorf("expected the freeze in the result, got %q: %q", result.Reason, result.Message)
+			}
+		})
+	}
+}
+
+func TestGuard_Check_UnreadableFreeze(t *testing.T) {
+	setupKubeconfig(t)
+
+	calendar := filepath.Join(t.TempDir(), "release.ics")
+	content := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:Release 2.0\n" +
+		"DTSTART:20261220T090000Z\nRRULE:FREQ=MONTHLY;BYSETPOS=-1\nEND:VEVENT\nEND:VCALENDAR\n"
+	if err := os.WriteFile(calendar, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	g := New(&config.Config{
+		FreezeCalendars: []config.FreezeCalendar{{File: calendar, Contexts: []string{"dev"}}},
+	})
+
+	for _, args := range [][]string{{"--context", "dev", "get", "pods"}, {"--context", "dev", "version"}} {
+		if result, err := g.Check(args); err != nil || result.Blocked {
+			t.Errorf("%q: expected to pass, got %+v: %v", args, result, err)
+		}
+	}
+	if _, err := g.Check([]string{"--context", "dev", "delete", "pod", "nginx"}); err == nil {
+		t.Error("expected error for a mutating command on a context with an unreadable freeze")
+	}
+}
+
+func TestGuard_Check_Force(t *testing.T) {
+	setupKubeconfig(t)
+
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{
+				Name:       "prod",
+				Namespaces: []string{"payments"},
+				Rules: []config.Rule{
+					{Commands: []string{"delete"}, Resources: []string{"pods"}, Action: config.ActionAllow},
+					{Commands: []string{"delete"}, Resources: []string{"ns"}, Action: config.ActionDeny},
+				},
+			},
+		},
+	}
+	g := New(cfg)
+
+	tests := []struct {
+		name   string
+		args   []string
+		action config.Action
+	}{
+		{
+			name:   "allowed by a rule",
+			args:   []string{"--context", "prod", "-n", "payments", "delete", "pod", "nginx"},
+			action: config.ActionAllow,
+		},
+		{
+			name:   "forced over an allow rule",
+			args:   []string{"--context", "prod", "-n", "payments", "delete", "pod", "nginx", "--force", "--grace-period=0"},
+			action: config.ActionBlock,
+		},
+		{
+			name:   "deny stays deny",
+			args:   []string{"--context", "prod", "delete", "ns", "payments", "--force"},
+			action: config.ActionDeny,
+		},
+		{
+			name:   "namespace not guarded",
+			args:   []string{"--context", "prod", "-n", "default", "delete", "pod", "nginx", "--force"},
+			action: config.ActionAllow,
+		},
+		{
+			name:   "unguarded context",
+			args:   []string{"--context", "dev", "delete", "pod", "nginx", "--force"},
+			action: config.ActionAllow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Action != tt.action {
+				t.Errorf("expected action %s, got %s (%s)", tt.action, result.Action, result.Reason)
+			}
+		})
 	}
 }
@@ -0,0 +1,28 @@
+package guard
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Kustomizer renders a kustomization (a directory or remote target, as given to -k) into manifests.
+type Kustomizer func(target string) ([]byte, error)
+
+// KubectlKustomize renders a kustomization with "kubectl kustomize".
+func KubectlKustomize(target string) ([]byte, error) {
+	kubectlPath, err := exec.LookPath("kubectl")
+	if err != nil {
+		return nil, err
+	}
+	out, err := exec.Command(kubectlPath, "kustomize", target).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return out, nil
+}
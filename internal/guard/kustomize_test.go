@@ -0,0 +1,80 @@
+package guard
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sivchari/kubectl-guard/internal/config"
+)
+
+func TestGuard_Check_Kustomize(t *testing.T) {
+	setupKubeconfig(t)
+
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{Name: "staging", Namespaces: []string{"critical"}},
+		},
+	}
+
+	var rendered []string
+	g := New(cfg)
+	g.SetKustomizer(func(target string) ([]byte, error) {
+		rendered = append(rendered, target)
+		return os.ReadFile("testdata/manifests/list.yaml")
+	})
+
+	result, err := g.Check([]string{"--context", "staging", "-n", "default", "apply", "-k", "overlays/prod"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rendered) != 1 || rendered[0] != "overlays/prod" {
+		t.Errorf("expected overlays/prod to be rendered, got %q", rendered)
+	}
+	if !result.Blocked {
+		t.Fatal("expected an object in a guarded namespace to be blocked")
+	}
+	if result.Kustomization != "overlays/prod" || result.Rendered != 2 {
+		t.Errorf("unexpected kustomization %q with %d objects", result.Kustomization, result.Rendered)
+	}
+	if !strings.Contains(result.Message, "kustomization: overlays/prod (2 objects rendered)") {
+		t.Errorf("expected the rendered object count in the message, got:\n%s", result.Message)
+	}
+	if !strings.Contains(result.Message, "object: serviceaccounts/deployer (namespace critical) from overlays/prod") {
+		t.Errorf("expected the blocked object in the message, got:\n%s", result.Message)
+	}
+}
+
+func TestGuard_Check_KustomizeError(t *testing.T) {
+	setupKubeconfig(t)
+
+	rendered := 0
+	kustomizer := func(string) ([]byte, error) {
+		rendered++
+		return nil, errors.New("missing kustomization.yaml")
+	}
+
+	g := New(&config.Config{GuardedContexts: []config.GuardedContext{{Name: "prod"}}})
+	g.SetKustomizer(kustomizer)
+	if _, err := g.Check([]string{"--context", "prod", "delete", "--kustomize=overlays/prod"}); err == nil {
+		t.Error("expected error when the kustomization cannot be rendered")
+	}
+
+	// no kustomization, no rendering
+	if _, err := g.Check([]string{"--context", "prod", "get", "pods"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// nothing guards the context, so the kustomization is left to kubectl
+	rendered = 0
+	g = New(&config.Config{})
+	g.SetKustomizer(kustomizer)
+	result, err := g.Check([]string{"--context", "prod", "delete", "--kustomize=overlays/prod"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rendered != 0 || result.Action != config.ActionAllow {
+		t.Errorf("expected an allowed command without rendering, rendered %d times: %+v", rendered, result)
+	}
+}
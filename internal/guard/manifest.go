@@ -0,0 +1,236 @@
+package guard
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxManifestSize caps the size of a manifest fetched from a URL.
+const maxManifestSize = 10 << 20
+
+// manifestExtensions are the files kubectl reads from a directory.
+var manifestExtensions = map[string]bool{
+	".json": true,
+	".yaml": true,
+	".yml":  true,
+}
+
+// httpClient fetches manifests from URLs.
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// manifest represents the identifying fields of a Kubernetes object in a manifest.
+type manifest struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	Items []manifest `yaml:"items"` // objects of List kinds such as v1/List or PodList
+}
+
+// group returns the API group of the manifest, e.g. "apps" for "apps/v1".
+func (m *manifest) group() string {
+	group, _, ok := strings.Cut(m.APIVersion, "/")
+	if !ok {
+		return ""
+	}
+	return group
+}
+
+// isList checks if the manifest is a list of objects, e.g. the output of "kubectl get -o yaml".
+func (m *manifest) isList() bool {
+	return strings.HasSuffix(m.Kind, "List") && m.Items != nil
+}
+
+// parseManifests parses multi-document YAML or JSON manifests, flattening List kinds.
+func parseManifests(data []byte) ([]manifest, error) {
+	var manifests []manifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var m manifest
+		if err := dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return manifests, nil
+			}
+			return nil, err
+		}
+		manifests = appendManifest(manifests, &m)
+	}
+}
+
+func appendManifest(manifests []manifest, m *manifest) []manifest {
+	if m.isList() {
+		for i := range m.Items {
+			manifests = appendManifest(manifests, &m.Items[i])
+		}
+		return manifests
+	}
+	if m.Kind == "" {
+		// empty documents
+		return manifests
+	}
+	return append(manifests, *m)
+}
+
+// isURL checks if a -f value is a URL, which kubectl fetches over HTTP.
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// manifestFiles expands a -f value into the files kubectl reads.
+// Directories yield their .json, .yaml and .yml files, including subdirectories when recursive.
+func manifestFiles(path string, recursive bool) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if manifestExtensions[filepath.Ext(p)] {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// fetchManifest downloads a manifest from a URL.
+func fetchManifest(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("manifest exceeds %d bytes", maxManifestSize)
+	}
+	return data, nil
+}
+
+// manifestOptions controls how manifests are read.
+type manifestOptions struct {
+	fetchURLs bool   // download -f URLs; otherwise they yield an uninspected object
+	stdin     []byte // buffered stdin for "-f -"; nil yields an uninspected object
+	kustomize Kustomizer
+}
+
+// manifestObjects reads the manifests given with -f/--filename, and renders the kustomization
+// given with -k/--kustomize, and resolves their objects.
+// Objects without a namespace get the namespace of the target.
+func (r *Resolver) manifestObjects(inv *invocation, namespace string, opts manifestOptions) ([]Object, error) {
+	var objects []Object
+	recursive := inv.enabled("recursive")
+	for _, path := range inv.values("filename") {
+		var sources []string
+		switch {
+		case path == "-" && opts.stdin == nil:
+			objects = append(objects, Object{Source: "stdin", Uninspected: true})
+			continue
+		case path == "-":
+			manifests, err := parseManifests(opts.stdin)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse manifest from stdin: %w", err)
+			}
+			for i := range manifests {
+				objects = append(objects, r.manifestObject(&manifests[i], namespace, "stdin"))
+			}
+			continue
+		case isURL(path) && !opts.fetchURLs:
+			objects = append(objects, Object{Source: path, Uninspected: true})
+			continue
+		case isURL(path):
+			sources = []string{path}
+		default:
+			files, err := manifestFiles(path, recursive)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read manifest: %w", err)
+			}
+			sources = files
+		}
+
+		for _, source := range sources {
+			var data []byte
+			var err error
+			if isURL(source) {
+				data, err = fetchManifest(source)
+			} else {
+				data, err = os.ReadFile(source)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read manifest %s: %w", source, err)
+			}
+			manifests, err := parseManifests(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse manifest %s: %w", source, err)
+			}
+			for i := range manifests {
+				objects = append(objects, r.manifestObject(&manifests[i], namespace, source))
+			}
+		}
+	}
+
+	if target := inv.value("kustomize"); target != "" {
+		data, err := opts.kustomize(target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render kustomization %s: %w", target, err)
+		}
+		manifests, err := parseManifests(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse kustomization %s: %w", target, err)
+		}
+		for i := range manifests {
+			o := r.manifestObject(&manifests[i], namespace, target)
+			o.Kustomized = true
+			objects = append(objects, o)
+		}
+	}
+	return objects, nil
+}
+
+func (r *Resolver) manifestObject(m *manifest, namespace, source string) Object {
+	resource, ok := r.ResolveKind(m.group(), m.Kind)
+	if !ok {
+		resource = Resource{Name: strings.ToLower(m.Kind), Kind: m.Kind, Group: m.group(), Namespaced: true, Unknown: true}
+	}
+
+	o := Object{Resource: resource, Name: m.Metadata.Name, Source: source}
+	if resource.Namespaced {
+		o.Namespace = m.Metadata.Namespace
+		if o.Namespace == "" {
+			o.Namespace = namespace
+		}
+	}
+	return o
+}
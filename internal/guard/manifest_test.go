@@ -0,0 +1,289 @@
+package guard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sivchari/kubectl-guard/internal/config"
+)
+
+func TestParseManifests(t *testing.T) {
+	data := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: payments
+---
+# comment only
+---
+{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}}
+`)
+
+	manifests, err := parseManifests(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(manifests) != 2 {
+		t.Fatalf("expected 2 manifests, got %d", len(manifests))
+	}
+	if m := manifests[0]; m.group() != "apps" || m.Kind != "Deployment" || m.Metadata.Name != "web" || m.Metadata.Namespace != "payments" {
+		t.Errorf("unexpected manifest: %+v", m)
+	}
+	if m := manifests[1]; m.group() != "" || m.Kind != "Service" || m.Metadata.Name != "web" {
+		t.Errorf("unexpected manifest: %+v", m)
+	}
+}
+
+func TestParseManifests_List(t *testing.T) {
+	data := []byte(`apiVersion: v1
+kind: PodList
+items:
+  - apiVersion: v1
+    kind: Pod
+    metadata:
+      name: a
+  - apiVersion: v1
+    kind: List
+    items:
+      - apiVersion: v1
+        kind: Pod
+        metadata:
+          name: b
+`)
+
+	manifests, err := parseManifests(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, m := range manifests {
+		names = append(names, m.Kind+"/"+m.Metadata.Name)
+	}
+	if expected := []string{"Pod/a", "Pod/b"}; !slices.Equal(names, expected) {
+		t.Errorf("expected %q, got %q", expected, names)
+	}
+}
+
+func TestParseManifests_Invalid(t *testing.T) {
+	if _, err := parseManifests([]byte("kind: [")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestManifestFiles(t *testing.T) {
+	dir := filepath.Join("testdata", "manifests", "tree")
+
+	files, err := manifestFiles(dir, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{filepath.Join(dir, "app.yaml")}; !slices.Equal(files, expected) {
+		t.Errorf("expected %q, got %q", expected, files)
+	}
+
+	files, err = manifestFiles(dir, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "app.yaml"), filepath.Join(dir, "critical", "settings.json")}
+	if !slices.Equal(files, expected) {
+		t.Errorf("expected %q, got %q", expected, files)
+	}
+
+	// files are read regardless of their extension
+	files, err = manifestFiles(filepath.Join(dir, "notes.txt"), false)
+	if err != nil || len(files) != 1 {
+		t.Errorf("expected the file itself, got %q (%v)", files, err)
+	}
+
+	if _, err := manifestFiles(filepath.Join(dir, "missing"), false); err == nil {
+		t.Error("expected error for a missing path")
+	}
+}
+
+func TestResolver_ManifestObjects(t *testing.T) {
+	inv := parseArgs([]string{"apply", "-f", "testdata/manifests/deploy.yaml,testdata/manifests/secrets.yaml", "-f", "https://example.com/app.yaml"})
+	objects, err := builtinResolver.manifestObjects(inv, "default", manifestOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, o := range objects {
+		got = append(got, o.String())
+	}
+	expected := []string{
+		"deployments.apps/web (namespace default)",
+		"configmaps/settings (namespace payments)",
+		"secrets/db-password (namespace payments)",
+		"(not inspected)",
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if _, err := builtinResolver.manifestObjects(parseArgs([]string{"apply", "-f", "testdata/manifests/missing.yaml"}), "default", manifestOptions{}); err == nil {
+		t.Error("expected error for a missing manifest")
+	}
+}
+
+func TestResolver_ManifestObjects_URL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	objects, err := builtinResolver.manifestObjects(parseArgs([]string{"apply", "-f", server.URL + "/app.yaml"}), "default", manifestOptions{fetchURLs: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(objects) != 1 || objects[0].String() != "configmaps/settings (namespace default)" {
+		t.Errorf("unexpected objects: %v", objects)
+	}
+
+	if _, err := builtinResolver.manifestObjects(parseArgs([]string{"apply", "-f", server.URL + "/missing.yaml"}), "default", manifestOptions{fetchURLs: true}); err == nil {
+		t.Error("expected error for a failed download")
+	}
+}
+
+func TestGuard_Check_Manifests(t *testing.T) {
+	setupKubeconfig(t)
+
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{Name: "staging", Namespaces: []string{"critical"}},
+		},
+	}
+	g := New(cfg)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+		verdicts []string
+	}{
+		{
+			name:     "manifest namespace wins over the default namespace",
+			args:     []string{"--context", "staging", "apply", "-f", "testdata/manifests/tree/app.yaml"},
+			expected: false,
+			verdicts: []string{"deployments.apps/web (namespace default): namespace not guarded"},
+		},
+		{
+			name:     "directory",
+			args:     []string{"--context", "staging", "apply", "-f", "testdata/manifests/tree"},
+			expected: false,
+			verdicts: []string{"deployments.apps/web (namespace default): namespace not guarded"},
+		},
+		{
+			name:     "recursive directory",
+			args:     []string{"--context", "staging", "apply", "-R", "-f", "testdata/manifests/tree"},
+			expected: true,
+			verdicts: []string{
+				"deployments.apps/web (namespace default): namespace not guarded",
+				"configmaps/settings (namespace critical): blocked command",
+			},
+		},
+		{
+			name:     "list items",
+			args:     []string{"--context", "staging", "delete", "-f", "testdata/manifests/list.yaml"},
+			expected: true,
+			verdicts: []string{
+				"services/web (namespace default): namespace not guarded",
+				"serviceaccounts/deployer (namespace critical): blocked command",
+			},
+		},
+		{
+			name:     "manifest without namespace uses the target namespace",
+			args:     []string{"--context", "staging", "replace", "-f", "testdata/manifests/deploy.yaml"},
+			expected: true,
+			verdicts: []string{"deployments.apps/web (namespace critical): blocked command"},
+		},
+		{
+			name:     "unknown kinds may be cluster-scoped",
+			args:     []string{"--context", "staging", "-n", "default", "apply", "-f", "testdata/manifests/clusterissuer.yaml"},
+			expected: true,
+			verdicts: []string{"clusterissuer.cert-manager.io/letsencrypt (namespace default): blocked command"},
+		},
+		{
+			name:     "URLs are not inspected",
+			args:     []string{"--context", "staging", "-n", "default", "create", "-f", "https://example.com/app.yaml"},
+			expected: true,
+			verdicts: []string{"(not inspected): blocked command"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := g.Check(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Blocked != tt.expected {
+				t.Errorf("expected blocked=%v, got %v", tt.expected, result.Blocked)
+			}
+			var verdicts []string
+			for _, v := range result.Objects {
+				verdicts = append(verdicts, v.Object.String()+": "+v.Reason)
+			}
+			if !slices.Equal(verdicts, tt.verdicts) {
+				t.Errorf("expected verdicts %q, got %q", tt.verdicts, verdicts)
+			}
+		})
+	}
+}
+
+func TestGuard_Check_ManifestsOnlyWhenGuarded(t *testing.T) {
+	setupKubeconfig(t)
+
+	fetched := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetched++
+		_, _ = w.Write([]byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: db-password\n"))
+	}))
+	t.Cleanup(server.Close)
+	args := []string{"--context", "dev", "apply", "-f", server.URL + "/app.yaml"}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		expected int
+	}{
+		{
+			name:     "unguarded context",
+			cfg:      &config.Config{FetchManifestURLs: true, GuardedContexts: []config.GuardedContext{{Name: "prod"}}},
+			expected: 0,
+		},
+		{
+			name:     "guarded context",
+			cfg:      &config.Config{FetchManifestURLs: true, GuardedContexts: []config.GuardedContext{{Name: "dev"}}},
+			expected: 1,
+		},
+		{
+			name: "protected resource",
+			cfg: &config.Config{
+				FetchManifestURLs:  true,
+				ProtectedResources: []config.ProtectedResource{{Context: "dev", Kind: "secret", Name: "db-*"}},
+			},
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched = 0
+			if _, err := New(tt.cfg).Check(args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fetched != tt.expected {
+				t.Errorf("expected %d downloads, got %d", tt.expected, fetched)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package guard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ReasonFlag gives the reason for overriding the guard, e.g. --guard-reason=INC-1234.
+// It is never passed to kubectl.
+const ReasonFlag = "--guard-reason"
+
+// ReasonEnv gives the reason for overriding the guard when the flag is not set.
+const ReasonEnv = "KUBECTL_GUARD_REASON"
+
+// ErrReasonRequired is returned by SetOverrideReason when the entry requires a reason and none is given.
+var ErrReasonRequired = errors.New("a reason is required to override this guard")
+
+// GetOverrideReason returns the reason given with --guard-reason=<reason> or --guard-reason <reason>
+// before the "--" terminator, or else by the environment variable.
+func GetOverrideReason(args []string) string {
+	reason, found := "", false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if v, ok := strings.CutPrefix(arg, ReasonFlag+"="); ok {
+			reason, found = v, true
+		} else if arg == ReasonFlag && i+1 < len(args) {
+			reason, found = args[i+1], true
+			i++
+		}
+	}
+	if found {
+		return reason
+	}
+	return os.Getenv(ReasonEnv)
+}
+
+// RemoveReasonFlag removes --guard-reason and its value from args.
+// Args after the "--" terminator belong to another program and are kept as is.
+func RemoveReasonFlag(args []string) []string {
+	result := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(result, args[i:]...)
+		case strings.HasPrefix(arg, ReasonFlag+"="):
+		case arg == ReasonFlag:
+			i++
+		default:
+			result = append(result, arg)
+		}
+	}
+	return result
+}
+
+// SetOverrideReason checks the reason against the entry's requirements and records it.
+func (r *CheckResult) SetOverrideReason(reason string) error {
+	reason = strings.TrimSpace(reason)
+	if r.RequireReason {
+		if reason == "" {
+			return ErrReasonRequired
+		}
+		if r.ReasonPattern != "" {
+			re, err := regexp.Compile(r.ReasonPattern)
+			if err != nil {
+				return err
+			}
+			if !re.MatchString(reason) {
+				return fmt.Errorf("reason %q does not match %s", reason, r.ReasonPattern)
+			}
+		}
+	}
+	r.OverrideReason = reason
+	return nil
+}
@@ -0,0 +1,178 @@
+package guard
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sivchari/kubectl-guard/internal/config"
+)
+
+func TestGetOverrideReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      string
+		expected string
+	}{
+		{
+			name:     "equals form",
+			args:     []string{"delete", "pod", "nginx", "--guard-reason=INC-42"},
+			expected: "INC-42",
+		},
+		{
+			name:     "space form",
+			args:     []string{"--guard-reason", "INC-42", "delete", "pod", "nginx"},
+			expected: "INC-42",
+		},
+		{
+			name:     "flag wins over the environment",
+			args:     []string{"delete", "pod", "nginx", "--guard-reason=INC-42"},
+			env:      "CHG-7",
+			expected: "INC-42",
+		},
+		{
+			name:     "environment",
+			args:     []string{"delete", "pod", "nginx"},
+			env:      "CHG-7",
+			expected: "CHG-7",
+		},
+		{
+			name:     "after terminator",
+			args:     []string{"exec", "nginx", "--", "cmd", "--guard-reason=INC-42"},
+			expected: "",
+		},
+		{
+			name:     "not given",
+			args:     []string{"delete", "pod", "nginx"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ReasonEnv, tt.env)
+			if reason := GetOverrideReason(tt.args); reason != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, reason)
+			}
+		})
+	}
+}
+
+func TestRemoveReasonFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "equals form",
+			args:     []string{"delete", "pod", "nginx", "--guard-reason=INC-42"},
+			expected: []string{"delete", "pod", "nginx"},
+		},
+		{
+			name:     "space form",
+			args:     []string{"--guard-reason", "INC-42", "delete", "pod", "nginx"},
+			expected: []string{"delete", "pod", "nginx"},
+		},
+		{
+			name:     "args after terminator are kept",
+			args:     []string{"--guard-reason=INC-42", "exec", "nginx", "--", "cmd", "--guard-reason=x"},
+			expected: []string{"exec", "nginx", "--", "cmd", "--guard-reason=x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := RemoveReasonFlag(tt.args); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCheckResult_SetOverrideReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   CheckResult
+		reason   string
+		expected string
+		err      error
+		errText  string
+	}{
+		{
+			name:     "not required",
+			result:   CheckResult{},
+			reason:   " rollback ",
+			expected: "rollback",
+		},
+		{
+			name:   "required and missing",
+			result: CheckResult{RequireReason: true},
+			reason: "  ",
+			err:    ErrReasonRequired,
+		},
+		{
+			name:     "required without pattern",
+			result:   CheckResult{RequireReason: true},
+			reason:   "rollback",
+			expected: "rollback",
+		},
+		{
+			name:     "matching the pattern",
+			result:   CheckResult{RequireReason: true, ReasonPattern: `^(INC|CHG)-[0-9]+$`},
+			reason:   "CHG-7",
+			expected: "CHG-7",
+		},
+		{
+			name:    "not matching the pattern",
+			result:  CheckResult{RequireReason: true, ReasonPattern: `^(INC|CHG)-[0-9]+$`},
+			reason:  "rollback",
+			errText: "does not match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.result.SetOverrideReason(tt.reason)
+			switch {
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+			case tt.errText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Fatalf("expected error containing %q, got %v", tt.errText, err)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.result.OverrideReason != tt.expected {
+				t.Errorf("expected reason %q, got %q", tt.expected, tt.result.OverrideReason)
+			}
+		})
+	}
+}
+
+func TestGuard_Check_RequireReason(t *testing.T) {
+	setupKubeconfig(t)
+
+	pattern := `^(INC|CHG)-[0-9]+$`
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{Name: "prod", RequireReason: true, ReasonPattern: pattern},
+		},
+	}
+
+	result, err := New(cfg).Check([]string{"--context", "prod", "delete", "pod", "nginx"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.RequireReason || result.ReasonPattern != pattern {
+		t.Errorf("expected the reason requirement, got %v %q", result.RequireReason, result.ReasonPattern)
+	}
+	if !strings.Contains(result.Message, ReasonFlag+"=<reason> matching "+pattern) {
+		t.Errorf("expected the reason hint, got %q", result.Message)
+	}
+}
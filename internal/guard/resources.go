@@ -0,0 +1,363 @@
+package guard
+
+import (
+	"slices"
+	"strings"
+)
+
+// Resource describes a Kubernetes API resource.
+type Resource struct {
+	Name       string // plural name, e.g. "deployments"
+	Singular   string
+	ShortNames []string
+	Kind       string
+	Group      string
+	Namespaced bool
+	// Unknown is set for types missing from the built-in resources and the discovery cache.
+	// They are assumed to be namespaced, but may be cluster-scoped.
+	Unknown bool
+}
+
+// mayBeClusterScoped checks if objects of the resource may be outside of any namespace.
+func (r Resource) mayBeClusterScoped() bool {
+	return !r.Namespaced || r.Unknown
+}
+
+// builtinResources lists the built-in resources known without API discovery.
+var builtinResources = []Resource{
+	{Name: "bindings", Singular: "binding", Kind: "Binding", Namespaced: true},
+	{Name: "componentstatuses", Singular: "componentstatus", ShortNames: []string{"cs"}, Kind: "ComponentStatus"},
+	{Name: "configmaps", Singular: "configmap", ShortNames: []string{"cm"}, Kind: "ConfigMap", Namespaced: true},
+	{Name: "endpoints", Singular: "endpoints", ShortNames: []string{"ep"}, Kind: "Endpoints", Namespaced: true},
+	{Name: "events", Singular: "event", ShortNames: []string{"ev"}, Kind: "Event", Namespaced: true},
+	{Name: "limitranges", Singular: "limitrange", ShortNames: []string{"limits"}, Kind: "LimitRange", Namespaced: true},
+	{Name: "namespaces", Singular: "namespace", ShortNames: []string{"ns"}, Kind: "Namespace"},
+	{Name: "nodes", Singular: "node", ShortNames: []string{"no"}, Kind: "Node"},
+	{Name: "persistentvolumeclaims", Singular: "persistentvolumeclaim", ShortNames: []string{"pvc"}, Kind: "PersistentVolumeClaim", Namespaced: true},
+	{Name: "persistentvolumes", Singular: "persistentvolume", ShortNames: []string{"pv"}, Kind: "PersistentVolume"},
+	{Name: "pods", Singular: "pod", ShortNames: []string{"po"}, Kind: "Pod", Namespaced: true},
+	{Name: "podtemplates", Singular: "podtemplate", Kind: "PodTemplate", Namespaced: true},
+	{Name: "replicationcontrollers", Singular: "replicationcontroller", ShortNames: []string{"rc"}, Kind: "ReplicationController", Namespaced: true},
+	{Name: "resourcequotas", Singular: "resourcequota", ShortNames: []string{"quota"}, Kind: "ResourceQuota", Namespaced: true},
+	{Name: "secrets", Singular: "secret", Kind: "Secret", Namespaced: true},
+	{Name: "serviceaccounts", Singular: "serviceaccount", ShortNames: []string{"sa"}, Kind: "ServiceAccount", Namespaced: true},
+	{Name: "services", Singular: "service", ShortNames: []string{"svc"}, Kind: "Service", Namespaced: true},
+	{Name: "mutatingwebhookconfigurations", Singular: "mutatingwebhookconfiguration", Kind: "MutatingWebhookConfiguration", Group: "admissionregistration.k8s.io"},
+	{Name: "validatingadmissionpolicies", Singular: "validatingadmissionpolicy", Kind: "ValidatingAdmissionPolicy", Group: "admissionregistration.k8s.io"},
+	{Name: "validatingadmissionpolicybindings", Singular: "validatingadmissionpolicybinding", Kind: "ValidatingAdmissionPolicyBinding", Group: "admissionregistration.k8s.io"},
+	{Name: "validatingwebhookconfigurations", Singular: "validatingwebhookconfiguration", Kind: "ValidatingWebhookConfiguration", Group: "admissionregistration.k8s.io"},
+	{Name: "customresourcedefinitions", Singular: "customresourcedefinition", ShortNames: []string{"crd", "crds"}, Kind: "CustomResourceDefinition", Group: "apiextensions.k8s.io"},
+	{Name: "apiservices", Singular: "apiservice", Kind: "APIService", Group: "apiregistration.k8s.io"},
+	{Name: "controllerrevisions", Singular: "controllerrevision", Kind: "ControllerRevision", Group: "apps", Namespaced: true},
+	{Name: "daemonsets", Singular: "daemonset", ShortNames: []string{"ds"}, Kind: "DaemonSet", Group: "apps", Namespaced: true},
+	{Name: "deployments", Singular: "deployment", ShortNames: []string{"deploy"}, Kind: "Deployment", Group: "apps", Namespaced: true},
+	{Name: "replicasets", Singular: "replicaset", ShortNames: []string{"rs"}, Kind: "ReplicaSet", Group: "apps", Namespaced: true},
+	{Name: "statefulsets", Singular: "statefulset", ShortNames: []string{"sts"}, Kind: "StatefulSet", Group: "apps", Namespaced: true},
+	{Name: "horizontalpodautoscalers", Singular: "horizontalpodautoscaler", ShortNames: []string{"hpa"}, Kind: "HorizontalPodAutoscaler", Group: "autoscaling", Namespaced: true},
+	{Name: "cronjobs", Singular: "cronjob", ShortNames: []string{"cj"}, Kind: "CronJob", Group: "batch", Namespaced: true},
+	{Name: "jobs", Singular: "job", Kind: "Job", Group: "batch", Namespaced: true},
+	{Name: "certificatesigningrequests", Singular: "certificatesigningrequest", ShortNames: []string{"csr"}, Kind: "CertificateSigningRequest", Group: "certificates.k8s.io"},
+	{Name: "leases", Singular: "lease", Kind: "Lease", Group: "coordination.k8s.io", Namespaced: true},
+	{Name: "endpointslices", Singular: "endpointslice", Kind: "EndpointSlice", Group: "discovery.k8s.io", Namespaced: true},
+	{Name: "flowschemas", Singular: "flowschema", Kind: "FlowSchema", Group: "flowcontrol.apiserver.k8s.io"},
+	{Name: "prioritylevelconfigurations", Singular: "prioritylevelconfiguration", Kind: "PriorityLevelConfiguration", Group: "flowcontrol.apiserver.k8s.io"},
+	{Name: "ingressclasses", Singular: "ingressclass", Kind: "IngressClass", Group: "networking.k8s.io"},
+	{Name: "ingresses", Singular: "ingress", ShortNames: []string{"ing"}, Kind: "Ingress", Group: "networking.k8s.io", Namespaced: true},
+	{Name: "networkpolicies", Singular: "networkpolicy", ShortNames: []string{"netpol"}, Kind: "NetworkPolicy", Group: "networking.k8s.io", Namespaced: true},
+	{Name: "runtimeclasses", Singular: "runtimeclass", Kind: "RuntimeClass", Group: "node.k8s.io"},
+	{Name: "poddisruptionbudgets", Singular: "poddisruptionbudget", ShortNames: []string{"pdb"}, Kind: "PodDisruptionBudget", Group: "policy", Namespaced: true},
+	{Name: "clusterrolebindings", Singular: "clusterrolebinding", Kind: "ClusterRoleBinding", Group: "rbac.authorization.k8s.io"},
+	{Name: "clusterroles", Singular: "clusterrole", Kind: "ClusterRole", Group: "rbac.authorization.k8s.io"},
+	{Name: "rolebindings", Singular: "rolebinding", Kind: "RoleBinding", Group: "rbac.authorization.k8s.io", Namespaced: true},
+	{Name: "roles", Singular: "role", Kind: "Role", Group: "rbac.authorization.k8s.io", Namespaced: true},
+	{Name: "priorityclasses", Singular: "priorityclass", ShortNames: []string{"pc"}, Kind: "PriorityClass", Group: "scheduling.k8s.io"},
+	{Name: "csidrivers", Singular: "csidriver", Kind: "CSIDriver", Group: "storage.k8s.io"},
+	{Name: "csinodes", Singular: "csinode", Kind: "CSINode", Group: "storage.k8s.io"},
+	{Name: "storageclasses", Singular: "storageclass", ShortNames: []string{"sc"}, Kind: "StorageClass", Group: "storage.k8s.io"},
+	{Name: "volumeattachments", Singular: "volumeattachment", Kind: "VolumeAttachment", Group: "storage.k8s.io"},
+}
+
+// String returns the resource name qualified with its group, e.g. "deployments.apps".
+func (r Resource) String() string {
+	if r.Group == "" {
+		return r.Name
+	}
+	return r.Name + "." + r.Group
+}
+
+// Resolver resolves resource types as typed on the kubectl command line.
+type Resolver struct {
+	resources []Resource
+}
+
+// NewResolver creates a new Resolver over the given resources.
+func NewResolver(resources []Resource) *Resolver {
+	return &Resolver{resources: resources}
+}
+
+var builtinResolver = NewResolver(builtinResources)
+
+// Resolve resolves a resource type by plural, singular, kind or short name,
+// optionally qualified with a group or version and group.
+func (r *Resolver) Resolve(name string) (Resource, bool) {
+	name = strings.ToLower(name)
+	resource, group, _ := strings.Cut(name, ".")
+	for _, res := range r.resources {
+		if group != "" && !matchGroup(res.Group, group) {
+			continue
+		}
+		if res.Name == resource || res.Singular == resource || strings.EqualFold(res.Kind, resource) {
+			return res, true
+		}
+		if slices.Contains(res.ShortNames, resource) {
+			return res, true
+		}
+	}
+	return Resource{}, false
+}
+
+// ResolveKind resolves a resource by API group and kind, as found in a manifest.
+func (r *Resolver) ResolveKind(group, kind string) (Resource, bool) {
+	for _, res := range r.resources {
+		if res.Group == group && res.Kind == kind {
+			return res, true
+		}
+	}
+	return Resource{}, false
+}
+
+// same checks if a resource type as typed for kubectl refers to the resource.
+func (r *Resolver) same(name string, resource Resource) bool {
+	if res, ok := r.Resolve(name); ok {
+		return res.Name == resource.Name && res.Group == resource.Group
+	}
+	return strings.EqualFold(name, resource.Name) || (resource.Kind != "" && strings.EqualFold(name, resource.Kind))
+}
+
+// allCategory is kubectl's "all" category of common namespaced resources, e.g. "kubectl delete all --all".
+const allCategory = "all"
+
+// allCategoryResources are the built-in resources in the "all" category.
+var allCategoryResources = []string{
+	"pods", "replicationcontrollers", "services", "daemonsets", "deployments", "replicasets",
+	"statefulsets", "horizontalpodautoscalers", "cronjobs", "jobs",
+}
+
+// inAllCategory checks if a resource type as typed for kubectl may be in the "all" category.
+// Unknown and custom resources may add themselves to it.
+func (r *Resolver) inAllCategory(name string) bool {
+	res, ok := r.Resolve(name)
+	switch {
+	case !ok:
+		return true
+	case slices.ContainsFunc(builtinResources, func(b Resource) bool { return b.Name == res.Name && b.Group == res.Group }):
+		return slices.Contains(allCategoryResources, res.Name)
+	}
+	return res.Namespaced
+}
+
+// mayBeNamespaced checks if a resource type as typed for kubectl may be namespaced.
+// Unknown types may be.
+func (r *Resolver) mayBeNamespaced(name string) bool {
+	res, ok := r.Resolve(name)
+	return !ok || res.Namespaced
+}
+
+// matchGroup matches "group" and "version.group" qualifiers such as "apps" or "v1.apps".
+func matchGroup(group, qualifier string) bool {
+	if qualifier == group {
+		return true
+	}
+	_, rest, ok := strings.Cut(qualifier, ".")
+	return ok && rest == group
+}
+
+// resourceArg represents a resource type and optional name given on the command line.
+type resourceArg struct {
+	resource string
+	name     string
+}
+
+// resourceCommands lists commands taking TYPE NAME or TYPE/NAME arguments,
+// mapped to the number of leading positionals (subcommands) to skip.
+var resourceCommands = map[string]int{
+	"annotate":  0,
+	"autoscale": 0,
+	"delete":    0,
+	"describe":  0,
+	"edit":      0,
+	"expose":    0,
+	"get":       0,
+	"label":     0,
+	"patch":     0,
+	"rollout":   1,
+	"scale":     0,
+	"set":       1,
+	"taint":     0,
+	"wait":      0,
+}
+
+// impliedResources lists commands that always act on one resource type.
+var impliedResources = map[string]string{
+	"certificate approve": "certificatesigningrequests",
+	"certificate deny":    "certificatesigningrequests",
+	"cordon":              "nodes",
+	"drain":               "nodes",
+	"run":                 "pods",
+	"uncordon":            "nodes",
+}
+
+// createSubtypes are the subtypes create takes before the name, e.g. "create secret generic NAME".
+var createSubtypes = map[string][]string{
+	"secret":  {"generic", "tls", "docker-registry"},
+	"service": {"clusterip", "nodeport", "loadbalancer", "externalname"},
+	"svc":     {"clusterip", "nodeport", "loadbalancer", "externalname"},
+}
+
+// resourceArgs returns the resources named on the command line,
+// in the "TYPE NAME...", "TYPE1,TYPE2 NAME..." and "TYPE/NAME..." forms.
+func (inv *invocation) resourceArgs() []resourceArg {
+	path := inv.commandPath()
+	if resource, ok := impliedResources[path]; ok {
+		args := make([]resourceArg, 0, len(inv.positionals))
+		for _, name := range inv.positionals[len(strings.Fields(path))-1:] {
+			args = append(args, resourceArg{resource: resource, name: name})
+		}
+		if len(args) == 0 {
+			args = append(args, resourceArg{resource: resource})
+		}
+		return args
+	}
+	if inv.command == "create" && len(inv.positionals) > 0 {
+		// "create namespace foo", "create deployment web", "create secret generic db", ...
+		args := []resourceArg{{resource: inv.positionals[0]}}
+		names := inv.positionals[1:]
+		if len(names) > 0 && slices.Contains(createSubtypes[args[0].resource], names[0]) {
+			names = names[1:]
+		}
+		if len(names) > 0 {
+			args[0].name = names[0]
+		}
+		return args
+	}
+
+	skip, ok := resourceCommands[inv.command]
+	if !ok || len(inv.positionals) <= skip {
+		return nil
+	}
+	positionals := inv.positionals[skip:]
+
+	var args []resourceArg
+	if strings.Contains(positionals[0], "/") {
+		for _, p := range positionals {
+			resource, name, ok := strings.Cut(p, "/")
+			if !ok {
+				// label changes, taints or container=image pairs
+				continue
+			}
+			args = append(args, resourceArg{resource: resource, name: name})
+		}
+		return args
+	}
+
+	var names []string
+	for _, p := range positionals[1:] {
+		if isResourceName(p) {
+			names = append(names, p)
+		}
+	}
+	for _, resource := range strings.Split(positionals[0], ",") {
+		if len(names) == 0 {
+			args = append(args, resourceArg{resource: resource})
+			continue
+		}
+		for _, name := range names {
+			args = append(args, resourceArg{resource: resource, name: name})
+		}
+	}
+	return args
+}
+
+// isResourceName reports whether a positional is a resource name rather than
+// a label or annotation change ("key=value", "key-"), a taint or a container=image pair.
+func isResourceName(arg string) bool {
+	return !strings.ContainsAny(arg, "=:") && !strings.HasSuffix(arg, "-")
+}
+
+// Object represents a resource targeted by a command.
+type Object struct {
+	Resource  Resource
+	Namespace string // empty for cluster-scoped resources
+	Name      string // empty when the command targets every object of a type, e.g. with a selector
+	Source    string // manifest or kustomization the object was read from, empty for the command line
+	// Kustomized is set for objects rendered from a -k kustomization.
+	Kustomized bool
+	// Uninspected is set for manifests that could not be read, e.g. URLs; such objects are always guarded.
+	Uninspected bool
+}
+
+// String returns the object in kubectl's TYPE/NAME form.
+func (o Object) String() string {
+	if o.Uninspected {
+		return "(not inspected)"
+	}
+	name := o.Name
+	if name == "" {
+		name = "*"
+	}
+	s := o.Resource.String() + "/" + name
+	if o.Namespace != "" {
+		s += " (namespace " + o.Namespace + ")"
+	}
+	return s
+}
+
+// objects resolves the resources named on the command line.
+// Unknown resource types are kept as typed and get the namespace, but may be cluster-scoped.
+func (r *Resolver) objects(inv *invocation, namespace string) []Object {
+	args := inv.resourceArgs()
+	objects := make([]Object, 0, len(args))
+	for _, arg := range args {
+		resource, ok := r.Resolve(arg.resource)
+		switch {
+		case ok:
+		case strings.EqualFold(arg.resource, allCategory):
+			resource = Resource{Name: allCategory, Namespaced: true}
+		default:
+			resource = Resource{Name: strings.ToLower(arg.resource), Namespaced: true, Unknown: true}
+		}
+		o := Object{Resource: resource, Name: arg.name}
+		if resource.Namespaced {
+			o.Namespace = namespace
+		}
+		objects = append(objects, o)
+	}
+	return objects
+}
+
+// resourceNames returns the distinct resources of the objects in order.
+func resourceNames(objects []Object) []string {
+	var names []string
+	for _, o := range objects {
+		if o.Uninspected {
+			continue
+		}
+		if name := o.Resource.String(); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// scopeOf returns the scope of the invocation acting on the objects.
+func scopeOf(inv *invocation, objects []Object) Scope {
+	if inv.enabled("all-namespaces") {
+		return ScopeAllNamespaces
+	}
+	for _, o := range objects {
+		if !o.Uninspected && o.Resource.mayBeClusterScoped() {
+			return ScopeCluster
+		}
+	}
+	return ScopeNamespace
+}
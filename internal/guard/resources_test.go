@@ -0,0 +1,147 @@
+package guard
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		input      string
+		expected   string
+		namespaced bool
+	}{
+		{input: "po", expected: "pods", namespaced: true},
+		{input: "pod", expected: "pods", namespaced: true},
+		{input: "Pods", expected: "pods", namespaced: true},
+		{input: "Pod", expected: "pods", namespaced: true},
+		{input: "deploy", expected: "deployments", namespaced: true},
+		{input: "deployments.apps", expected: "deployments", namespaced: true},
+		{input: "deployments.v1.apps", expected: "deployments", namespaced: true},
+		{input: "ns", expected: "namespaces"},
+		{input: "crd", expected: "customresourcedefinitions"},
+		{input: "clusterrole", expected: "clusterroles"},
+		{input: "pv", expected: "persistentvolumes"},
+		{input: "pvc", expected: "persistentvolumeclaims", namespaced: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			r, ok := builtinResolver.Resolve(tt.input)
+			if !ok {
+				t.Fatalf("expected %q to resolve", tt.input)
+			}
+			if r.Name != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, r.Name)
+			}
+			if r.Namespaced != tt.namespaced {
+				t.Errorf("expected namespaced=%v, got %v", tt.namespaced, r.Namespaced)
+			}
+		})
+	}
+
+	for _, input := range []string{"widgets", "deployments.batch"} {
+		if _, ok := builtinResolver.Resolve(input); ok {
+			t.Errorf("expected %q not to resolve", input)
+		}
+	}
+}
+
+func TestParseArgs_ResourceArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []resourceArg
+	}{
+		{
+			name:     "type and names",
+			args:     []string{"delete", "pod", "a", "b"},
+			expected: []resourceArg{{"pod", "a"}, {"pod", "b"}},
+		},
+		{
+			name:     "type/name form",
+			args:     []string{"delete", "deploy/web", "svc/web"},
+			expected: []resourceArg{{"deploy", "web"}, {"svc", "web"}},
+		},
+		{
+			name:     "comma separated types",
+			args:     []string{"delete", "pods,svc", "-l", "app=web"},
+			expected: []resourceArg{{"pods", ""}, {"svc", ""}},
+		},
+		{
+			name:     "label changes are not names",
+			args:     []string{"label", "pod", "nginx", "app=web", "tier-"},
+			expected: []resourceArg{{"pod", "nginx"}},
+		},
+		{
+			name:     "subcommand is skipped",
+			args:     []string{"set", "image", "deploy/web", "web=nginx:2"},
+			expected: []resourceArg{{"deploy", "web"}},
+		},
+		{
+			name:     "implied node",
+			args:     []string{"drain", "node-1", "--ignore-daemonsets"},
+			expected: []resourceArg{{"nodes", "node-1"}},
+		},
+		{
+			name:     "create subcommand",
+			args:     []string{"create", "namespace", "payments"},
+			expected: []resourceArg{{"namespace", "payments"}},
+		},
+		{
+			name:     "create secret subtype",
+			args:     []string{"create", "secret", "generic", "db-password", "--from-literal=password=x"},
+			expected: []resourceArg{{"secret", "db-password"}},
+		},
+		{
+			name:     "create service subtype",
+			args:     []string{"create", "service", "nodeport", "web", "--tcp=80:8080"},
+			expected: []resourceArg{{"service", "web"}},
+		},
+		{
+			name:     "create subtype without a name",
+			args:     []string{"create", "secret", "tls"},
+			expected: []resourceArg{{"secret", ""}},
+		},
+		{
+			name:     "read commands without resources",
+			args:     []string{"logs", "nginx"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseArgs(tt.args).resourceArgs()
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseArgs_Scope(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected Scope
+	}{
+		{args: []string{"delete", "pod", "nginx"}, expected: ScopeNamespace},
+		{args: []string{"delete", "pods", "--all", "-A"}, expected: ScopeAllNamespaces},
+		{args: []string{"delete", "pods", "--all", "--all-namespaces"}, expected: ScopeAllNamespaces},
+		{args: []string{"delete", "pods", "--all-namespaces=false"}, expected: ScopeNamespace},
+		{args: []string{"drain", "node-1"}, expected: ScopeCluster},
+		{args: []string{"delete", "ns", "payments"}, expected: ScopeCluster},
+		{args: []string{"delete", "pv,pvc", "data"}, expected: ScopeCluster},
+		{args: []string{"certificate", "approve", "csr-1"}, expected: ScopeCluster},
+		// unknown types may be cluster-scoped
+		{args: []string{"delete", "widgets", "w"}, expected: ScopeCluster},
+		{args: []string{"delete", "all", "--all"}, expected: ScopeNamespace},
+	}
+
+	for _, tt := range tests {
+		inv := parseArgs(tt.args)
+		if result := scopeOf(inv, builtinResolver.objects(inv, "default")); result != tt.expected {
+			t.Errorf("%q: expected %q, got %q", tt.args, tt.expected, result)
+		}
+	}
+}
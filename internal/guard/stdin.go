@@ -0,0 +1,32 @@
+package guard
+
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+// MaxStdinSize caps how much of stdin is buffered to inspect a "-f -" manifest.
+const MaxStdinSize = 10 << 20
+
+// ErrStdinTooLarge is returned by ReadStdin when stdin exceeds MaxStdinSize.
+var ErrStdinTooLarge = fmt.Errorf("stdin manifest exceeds %d bytes", MaxStdinSize)
+
+// ReadsStdin checks if kubectl would read a manifest from stdin, i.e. "-f -".
+func ReadsStdin(args []string) bool {
+	return slices.Contains(parseArgs(args).values("filename"), "-")
+}
+
+// ReadStdin buffers up to MaxStdinSize bytes of r.
+// When r is larger, the bytes read so far are returned with ErrStdinTooLarge
+// so that they can still be replayed in front of the rest of r.
+func ReadStdin(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxStdinSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxStdinSize {
+		return data, ErrStdinTooLarge
+	}
+	return data, nil
+}
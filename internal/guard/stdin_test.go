@@ -0,0 +1,159 @@
+package guard
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sivchari/kubectl-guard/internal/config"
+)
+
+func TestReadsStdin(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected bool
+	}{
+		{args: []string{"apply", "-f", "-"}, expected: true},
+		{args: []string{"apply", "--filename=-"}, expected: true},
+		{args: []string{"apply", "-f", "deploy.yaml,-"}, expected: true},
+		{args: []string{"apply", "-f", "deploy.yaml"}, expected: false},
+		{args: []string{"logs", "-f", "-"}, expected: false},
+		{args: []string{"exec", "-i", "nginx", "--", "cat", "-f", "-"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		if result := ReadsStdin(tt.args); result != tt.expected {
+			t.Errorf("ReadsStdin(%q) = %v, expected %v", tt.args, result, tt.expected)
+		}
+	}
+}
+
+func TestReadStdin(t *testing.T) {
+	data, err := ReadStdin(strings.NewReader("kind: Pod\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "kind: Pod\n" {
+		t.Errorf("unexpected data: %q", data)
+	}
+
+	large := bytes.Repeat([]byte("#"), MaxStdinSize+10)
+	data, err = ReadStdin(bytes.NewReader(large))
+	if !errors.Is(err, ErrStdinTooLarge) {
+		t.Fatalf("expected ErrStdinTooLarge, got %v", err)
+	}
+	if !bytes.Equal(data, large[:len(data)]) || len(data) <= MaxStdinSize {
+		t.Errorf("expected the bytes read so far, got %d bytes", len(data))
+	}
+}
+
+func TestGuard_Check_Stdin(t *testing.T) {
+	setupKubeconfig(t)
+
+	cfg := &config.Config{
+		GuardedContexts: []config.GuardedContext{
+			{Name: "staging", Namespaces: []string{"critical"}},
+		},
+	}
+	args := []string{"--context", "staging", "-n", "default", "apply", "-f", "-"}
+
+	// not buffered: unknown objects are guarded
+	result, err := New(cfg).Check(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Blocked {
+		t.Error("expected an uninspected stdin manifest to be blocked")
+	}
+
+	g := New(cfg)
+	g.SetStdin([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n"))
+	result, err = g.Check(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Blocked {
+		t.Error("expected a manifest in an unguarded namespace to pass")
+	}
+
+	g.SetStdin([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n  namespace: critical\n"))
+	result, err = g.Check(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Blocked || len(result.Objects) != 1 || result.Objects[0].Object.Source != "stdin" {
+		t.Errorf("expected the stdin object to be blocked, got %+v", result.Objects)
+	}
+}
+
+func TestGuard_Inspects(t *testing.T) {
+	setupKubeconfig(t)
+
+	g := New(&config.Config{
+		GuardedContexts:    []config.GuardedContext{{Name: "staging"}},
+		ProtectedResources: []config.ProtectedResource{{Context: "prod", Kind: "secret", Name: "db-*"}},
+	})
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{name: "guarded context", args: []string{"--context", "staging", "apply", "-f", "-"}, expected: true},
+		{name: "protected resource", args: []string{"--context", "prod", "apply", "-f", "-"}, expected: true},
+		{name: "protected resource on a read command", args: []string{"--context", "prod", "get", "-f", "-"}},
+		{name: "unguarded context", args: []string{"--context", "dev", "apply", "-f", "-"}},
+		{name: "dry run", args: []string{"--context", "staging", "apply", "-f", "-", "--dry-run=client"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inspects, err := g.Inspects(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if inspects != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, inspects)
+			}
+		})
+	}
+}
+
+func TestExecCommand_Stdin(t *testing.T) {
+	sh, err := os.Stat("/bin/sh")
+	if err != nil || sh.IsDir() {
+		t.Skip("/bin/sh is not available")
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+	data := []byte("apiVersion: v1\r\nkind: ConfigMap\n\x00binary")
+	if code, err := execCommand("/bin/sh", []string{"-c", "cat > " + out}, bytes.NewReader(data)); err != nil || code != 0 {
+		t.Fatalf("unexpected exit code %d: %v", code, err)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("expected the exact bytes to be replayed, got %q", got)
+	}
+}
+
+func TestExecCommand_ExitCode(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("/bin/sh is not available")
+	}
+
+	code, err := execCommand("/bin/sh", []string{"-c", "exit 3"}, strings.NewReader(""))
+	if err != nil || code != 3 {
+		t.Errorf("expected exit code 3, got %d (%v)", code, err)
+	}
+
+	if _, err := execCommand(filepath.Join(t.TempDir(), "missing"), nil, strings.NewReader("")); err == nil {
+		t.Error("expected error for a missing command")
+	}
+}
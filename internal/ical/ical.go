@@ -0,0 +1,438 @@
+// Package ical reads events from iCalendar (RFC 5545) files.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxFileSize bounds the calendar files read.
+const maxFileSize = 10 << 20
+
+// Event is a calendar event.
+// A recurring event stands for its first occurrence; Expand returns the others.
+type Event struct {
+	UID        string
+	Summary    string
+	Categories []string
+	Status     string // e.g. "CONFIRMED" or "CANCELLED"
+	Start, End time.Time
+	AllDay     bool
+	// RecurrenceID is set on an event replacing the occurrence of a recurring event starting at that time.
+	RecurrenceID time.Time
+	// Err is set for an event that could not be read, e.g. with an unsupported recurrence rule;
+	// only the properties read before the error are known, and its times are not.
+	Err error
+
+	rule            *rule
+	rdates, exdates []time.Time
+}
+
+// Recurring checks if the event has a recurrence rule or recurrence dates.
+func (e *Event) Recurring() bool {
+	return e.rule != nil || len(e.rdates) > 0
+}
+
+// Contains checks if the event is in progress at the time.
+func (e *Event) Contains(t time.Time) bool {
+	return !t.Before(e.Start) && t.Before(e.End)
+}
+
+// Cancelled checks if the event was cancelled.
+func (e *Event) Cancelled() bool {
+	return strings.EqualFold(e.Status, "CANCELLED")
+}
+
+// HasCategory checks if the event has the category, ignoring case.
+func (e *Event) HasCategory(category string) bool {
+	for _, c := range e.Categories {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFile reads the events of a calendar file.
+func ParseFile(path string, loc *time.Location) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	events, err := Parse(io.LimitReader(f, maxFileSize), loc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return events, nil
+}
+
+// Parse reads the events of a calendar.
+// Floating times and dates, which carry no timezone, are taken in loc.
+// Events are read one at a time: an event that cannot be read is returned with Err set,
+// and content lines outside of events are not looked at.
+func Parse(r io.Reader, loc *time.Location) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var event *rawEvent
+	depth := 0 // components nested in the event, e.g. VALARM
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		p, err := parseLine(line)
+		switch {
+		case err != nil && event != nil:
+			event.fail(fmt.Errorf("content line %d: %w", i+1, err))
+			continue
+		case err != nil:
+			continue
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT") && event == nil:
+			event = &rawEvent{}
+		case event == nil:
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END" && depth > 0:
+			depth--
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT"):
+			events = append(events, event.event(loc))
+			event = nil
+		case depth == 0:
+			event.set(p)
+		}
+	}
+	if event != nil {
+		event.fail(errors.New("unterminated event"))
+		events = append(events, event.event(loc))
+	}
+	return events, nil
+}
+
+// unfold joins the continuation lines, starting with a space or a tab, to the lines they continue.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxFileSize)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// property is a content line such as "DTSTART;TZID=Europe/Berlin:20261220T100000".
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func parseLine(line string) (*property, error) {
+	// the value starts at the first colon outside of quoted parameter values
+	quoted := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return nil, fmt.Errorf("invalid content line %q", line)
+	}
+
+	p := &property{params: map[string]string{}, value: line[colon+1:]}
+	parts := strings.Split(line[:colon], ";")
+	p.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return p, nil
+}
+
+// rawEvent holds the properties of an event until its end.
+type rawEvent struct {
+	uid, summary, status     string
+	categories               []string
+	start, end, recurrenceID *property
+	duration                 string
+	rrules                   []string
+	rdates, exdates          []*property
+	err                      error // the first error reading the event
+}
+
+// fail records an error reading the event, keeping the first one.
+func (e *rawEvent) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+// event resolves the event, setting Err when it cannot be read.
+func (e *rawEvent) event(loc *time.Location) Event {
+	event, err := e.resolve(loc)
+	if e.err != nil {
+		err = e.err
+	}
+	if err != nil {
+		event = Event{UID: e.uid, Summary: e.summary, Categories: e.categories, Status: e.status, Err: err}
+	}
+	return event
+}
+
+func (e *rawEvent) set(p *property) {
+	switch p.name {
+	case "UID":
+		e.uid = p.value
+	case "SUMMARY":
+		e.summary = unescape(p.value)
+	case "STATUS":
+		e.status = strings.ToUpper(p.value)
+	case "CATEGORIES":
+		for _, c := range splitText(p.value) {
+			if c = strings.TrimSpace(unescape(c)); c != "" {
+				e.categories = append(e.categories, c)
+			}
+		}
+	case "DTSTART":
+		e.start = p
+	case "DTEND":
+		e.end = p
+	case "DURATION":
+		e.duration = p.value
+	case "RRULE":
+		e.rrules = append(e.rrules, p.value)
+	case "RDATE":
+		e.rdates = append(e.rdates, p)
+	case "EXDATE":
+		e.exdates = append(e.exdates, p)
+	case "RECURRENCE-ID":
+		e.recurrenceID = p
+	}
+}
+
+func (e *rawEvent) resolve(loc *time.Location) (Event, error) {
+	event := Event{UID: e.uid, Summary: e.summary, Categories: e.categories, Status: e.status}
+	if e.start == nil {
+		return event, errors.New("missing DTSTART")
+	}
+	start, allDay, err := parseTime(e.start, loc)
+	if err != nil {
+		return event, err
+	}
+	event.Start, event.AllDay = start, allDay
+
+	switch {
+	case e.end != nil:
+		if event.End, _, err = parseTime(e.end, loc); err != nil {
+			return event, err
+		}
+	case e.duration != "":
+		d, err := parseDuration(e.duration)
+		if err != nil {
+			return event, err
+		}
+		event.End = start.Add(d)
+	case allDay:
+		// a date without an end lasts the day
+		event.End = start.AddDate(0, 0, 1)
+	default:
+		event.End = start
+	}
+	if event.End.Before(event.Start) {
+		return event, errors.New("event ends before it starts")
+	}
+	return event, e.resolveRecurrence(&event, loc)
+}
+
+// resolveRecurrence sets the recurrence of the event.
+func (e *rawEvent) resolveRecurrence(event *Event, loc *time.Location) error {
+	switch len(e.rrules) {
+	case 0:
+	case 1:
+		r, err := parseRule(e.rrules[0], event.Start)
+		if err != nil {
+			return err
+		}
+		event.rule = r
+	default:
+		return errors.New("multiple RRULE")
+	}
+
+	var err error
+	if event.rdates, err = parseTimes(e.rdates, loc); err != nil {
+		return err
+	}
+	if event.exdates, err = parseTimes(e.exdates, loc); err != nil {
+		return err
+	}
+	if e.recurrenceID != nil {
+		if r := e.recurrenceID.params["RANGE"]; r != "" {
+			return fmt.Errorf("unsupported RECURRENCE-ID range %s", r)
+		}
+		if event.RecurrenceID, _, err = parseTime(e.recurrenceID, loc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTimes parses the comma-separated values of RDATE or EXDATE properties.
+func parseTimes(props []*property, loc *time.Location) ([]time.Time, error) {
+	var times []time.Time
+	for _, p := range props {
+		if strings.EqualFold(p.params["VALUE"], "PERIOD") {
+			return nil, fmt.Errorf("unsupported %s periods", p.name)
+		}
+		for _, value := range strings.Split(p.value, ",") {
+			t, _, err := parseTime(&property{name: p.name, params: p.params, value: value}, loc)
+			if err != nil {
+				return nil, err
+			}
+			times = append(times, t)
+		}
+	}
+	return times, nil
+}
+
+// parseTime parses a DTSTART or DTEND value and reports whether it is a date.
+// UTC times end with "Z", local times carry a TZID parameter, and floating times and dates are taken in loc.
+func parseTime(p *property, loc *time.Location) (time.Time, bool, error) {
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(p.value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", p.value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s %q", p.name, p.value)
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(p.value, "Z") {
+		t, err := time.Parse("20060102T150405Z", p.value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s %q", p.name, p.value)
+		}
+		return t, false, nil
+	}
+	if tzid := p.params["TZID"]; tzid != "" {
+		// names outside the IANA database, e.g. from Windows, fall back to loc
+		if l, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", p.value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s %q", p.name, p.value)
+	}
+	return t, false, nil
+}
+
+// parseDuration parses an iCalendar duration such as "PT1H30M", "P2D" or "P1W".
+func parseDuration(s string) (time.Duration, error) {
+	rest := strings.TrimPrefix(strings.TrimPrefix(s, "+"), "-")
+	negative := strings.HasPrefix(s, "-")
+	rest, ok := strings.CutPrefix(rest, "P")
+	if !ok || rest == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	var d time.Duration
+	inTime := false
+	number := ""
+	units := 0
+	for _, c := range rest {
+		switch {
+		case c >= '0' && c <= '9':
+			number += string(c)
+			continue
+		case c == 'T' && !inTime && number == "":
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		var unit time.Duration
+		switch {
+		case c == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case c == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case c == 'H' && inTime:
+			unit = time.Hour
+		case c == 'M' && inTime:
+			unit = time.Minute
+		case c == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += time.Duration(n) * unit
+		number = ""
+		units++
+	}
+	if number != "" || units == 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
+
+// splitText splits a list value at the commas that are not escaped.
+func splitText(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescape decodes the escaped characters of a text value.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
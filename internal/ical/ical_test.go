@@ -0,0 +1,187 @@
+package ical
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const calendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Release Calendar//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:release-2@example.com\r\n" +
+	"SUMMARY:Release 2.0 freeze\\, all teams\r\n" +
+	"CATEGORIES:FREEZE,Release\r\n" +
+	"DTSTART:20261220T090000Z\r\n" +
+	"DTEND:20261222T180000Z\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"SUMMARY:Reminder\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:year-end@example.com\r\n" +
+	"SUMMARY:Year-end freeze with a long summary that is folded over\r\n" +
+	"  two lines\r\n" +
+	"DTSTART;VALUE=DATE:20261224\r\n" +
+	"DTEND;VALUE=DATE:20270102\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:maintenance@example.com\r\n" +
+	"SUMMARY:Maintenance\r\n" +
+	"CATEGORIES:Ops\r\n" +
+	"CATEGORIES:Network\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20261110T220000\r\n" +
+	"DURATION:PT2H30M\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(calendar), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	release := events[0]
+	if release.Summary != "Release 2.0 freeze, all teams" {
+		t.Errorf("unexpected summary %q", release.Summary)
+	}
+	if !release.HasCategory("freeze") || !release.HasCategory("RELEASE") || release.HasCategory("Reminder") {
+		t.Errorf("unexpected categories %q", release.Categories)
+	}
+	if !release.Start.Equal(time.Date(2026, 12, 20, 9, 0, 0, 0, time.UTC)) ||
+		!release.End.Equal(time.Date(2026, 12, 22, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected times %s to %s", release.Start, release.End)
+	}
+
+	yearEnd := events[1]
+	if yearEnd.Summary != "Year-end freeze with a long summary that is folded over two lines" {
+		t.Errorf("expected an unfolded summary, got %q", yearEnd.Summary)
+	}
+	if !yearEnd.AllDay || !yearEnd.Contains(time.Date(2027, 1, 1, 23, 0, 0, 0, time.UTC)) ||
+		yearEnd.Contains(time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected all-day event %+v", yearEnd)
+	}
+
+	maintenance := events[2]
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+	start := time.Date(2026, 11, 10, 22, 0, 0, 0, berlin)
+	if !maintenance.Start.Equal(start) || !maintenance.End.Equal(start.Add(150*time.Minute)) {
+		t.Errorf("unexpected times %s to %s", maintenance.Start, maintenance.End)
+	}
+	if !maintenance.Cancelled() || len(maintenance.Categories) != 2 {
+		t.Errorf("unexpected event %+v", maintenance)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unterminated event": "BEGIN:VEVENT\nDTSTART:20261220T090000Z\n",
+		"missing start":      "BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n",
+		"invalid start":      "BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n",
+		"invalid duration":   "BEGIN:VEVENT\nDTSTART:20261220T090000Z\nDURATION:2 hours\nEND:VEVENT\n",
+		"ends before start":  "BEGIN:VEVENT\nDTSTART:20261220T090000Z\nDTEND:20261219T090000Z\nEND:VEVENT\n",
+		"invalid line":       "BEGIN:VEVENT\nnot a content line\nEND:VEVENT\n",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			events, err := Parse(strings.NewReader(content), time.UTC)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(events) != 1 || events[0].Err == nil {
+				t.Errorf("expected an event with an error, got %+v", events)
+			}
+		})
+	}
+}
+
+func TestParse_SkipsInvalidEvents(t *testing.T) {
+	content := "BEGIN:VCALENDAR\n" +
+		"not a content line\n" +
+		"BEGIN:VEVENT\n" +
+		"SUMMARY:Board meeting\n" +
+		"CATEGORIES:Meeting\n" +
+		"DTSTART:20261220T090000Z\n" +
+		"RRULE:FREQ=MONTHLY;BYDAY=MO;BYSETPOS=-1\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"SUMMARY:Release freeze\n" +
+		"DTSTART:20261221T090000Z\n" +
+		"DTEND:20261222T090000Z\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n"
+	events, err := Parse(strings.NewReader(content), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	if board := events[0]; board.Err == nil || board.Summary != "Board meeting" || !board.HasCategory("meeting") {
+		t.Errorf("expected the board meeting with its summary, categories and an error, got %+v", board)
+	}
+	if release := events[1]; release.Err != nil || !release.Contains(time.Date(2026, 12, 21, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the release freeze to be read, got %+v", release)
+	}
+	if expanded := Expand(events, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)); len(expanded) != 1 {
+		t.Errorf("expected only the release freeze to be expanded, got %+v", expanded)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"PT1H30M": 90 * time.Minute,
+		"P2D":     48 * time.Hour,
+		"P1W":     7 * 24 * time.Hour,
+		"P1DT12H": 36 * time.Hour,
+		"-PT15M":  -15 * time.Minute,
+		"+PT10S":  10 * time.Second,
+	}
+	for s, expected := range tests {
+		d, err := parseDuration(s)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", s, err)
+			continue
+		}
+		if d != expected {
+			t.Errorf("%s: expected %s, got %s", s, expected, d)
+		}
+	}
+
+	for _, s := range []string{"", "P", "PT", "1H", "PT1D", "P1H", "PT1H30"} {
+		if _, err := parseDuration(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.ics")
+	if err := os.WriteFile(path, []byte(calendar), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	events, err := ParseFile(path, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Errorf("expected 3 events, got %d", len(events))
+	}
+
+	if _, err := ParseFile(filepath.Join(t.TempDir(), "missing.ics"), time.UTC); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
@@ -0,0 +1,382 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds the periods a recurrence rule is expanded over, e.g. about 270 years of days.
+const maxPeriods = 100000
+
+// rule is a recurrence rule (RRULE).
+// Rules with BYSECOND, BYMINUTE, BYHOUR, BYYEARDAY, BYWEEKNO or BYSETPOS parts are not supported.
+type rule struct {
+	freq       string // DAILY, WEEKLY, MONTHLY or YEARLY
+	interval   int
+	count      int
+	until      time.Time // the last possible start, zero when open
+	byDay      []weekday
+	byMonthDay []int
+	byMonth    []time.Month
+	weekStart  time.Weekday
+}
+
+// weekday is a BYDAY value such as "MO", "1MO" or "-1FR"; n is zero for every such day.
+type weekday struct {
+	n   int
+	day time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseRule parses an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,TU".
+// UNTIL is taken in the location of start when it is floating or a date; a date includes the whole day.
+func parseRule(value string, start time.Time) (*rule, error) {
+	r := &rule{interval: 1, weekStart: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		name, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid RRULE %q", value)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			r.freq = strings.ToUpper(v)
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(v)
+			if err == nil && r.interval < 1 {
+				err = errors.New("interval must be positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(v)
+			if err == nil && r.count < 1 {
+				err = errors.New("count must be positive")
+			}
+		case "UNTIL":
+			var date bool
+			r.until, date, err = parseTime(&property{name: "UNTIL", value: v}, start.Location())
+			if date {
+				r.until = r.until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+		case "BYDAY":
+			r.byDay, err = parseWeekdays(v)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseNumbers(v, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseNumbers(v, 12)
+			for _, m := range months {
+				if m < 0 {
+					err = fmt.Errorf("invalid month %d", m)
+				}
+				r.byMonth = append(r.byMonth, time.Month(m))
+			}
+			slices.Sort(r.byMonth)
+		case "WKST":
+			day, known := weekdays[strings.ToUpper(v)]
+			if !known {
+				err = fmt.Errorf("invalid weekday %q", v)
+			}
+			r.weekStart = day
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %q: %w", value, err)
+		}
+	}
+	if err := r.validate(); err != nil {
+		return nil, fmt.Errorf("invalid RRULE %q: %w", value, err)
+	}
+	return r, nil
+}
+
+func (r *rule) validate() error {
+	ordinal := slices.ContainsFunc(r.byDay, func(w weekday) bool { return w.n != 0 })
+	switch r.freq {
+	case "DAILY":
+	case "WEEKLY":
+		if len(r.byMonthDay) > 0 {
+			return errors.New("BYMONTHDAY is not allowed with FREQ=WEEKLY")
+		}
+	case "MONTHLY":
+		return nil
+	case "YEARLY":
+		if len(r.byDay) > 0 && len(r.byMonth) == 0 {
+			return errors.New("unsupported BYDAY without BYMONTH with FREQ=YEARLY")
+		}
+		return nil
+	case "":
+		return errors.New("missing FREQ")
+	default:
+		return fmt.Errorf("unsupported FREQ %s", r.freq)
+	}
+	if ordinal {
+		return fmt.Errorf("numbered BYDAY is not allowed with FREQ=%s", r.freq)
+	}
+	return nil
+}
+
+func parseWeekdays(s string) ([]weekday, error) {
+	var days []weekday
+	for _, v := range strings.Split(s, ",") {
+		v = strings.ToUpper(v)
+		if len(v) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", v)
+		}
+		day, ok := weekdays[v[len(v)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", v)
+		}
+		w := weekday{day: day}
+		if n := v[:len(v)-2]; n != "" {
+			var err error
+			if w.n, err = strconv.Atoi(n); err != nil || w.n == 0 || w.n < -5 || w.n > 5 {
+				return nil, fmt.Errorf("invalid weekday %q", v)
+			}
+		}
+		days = append(days, w)
+	}
+	return days, nil
+}
+
+// parseNumbers parses a list of numbers from 1 to limit, or from -limit to -1 counting from the end.
+func parseNumbers(s string, limit int) ([]int, error) {
+	var numbers []int
+	for _, v := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil || n == 0 || n < -limit || n > limit {
+			return nil, fmt.Errorf("invalid number %q", v)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// starts returns the starts of the occurrences before limit, beginning with start itself.
+func (r *rule) starts(start, limit time.Time) []time.Time {
+	starts := []time.Time{start}
+	for p := 0; p < maxPeriods; p++ {
+		candidates, periodStart := r.period(start, p)
+		if !periodStart.Before(limit) || (!r.until.IsZero() && periodStart.After(r.until)) {
+			break
+		}
+		for _, t := range candidates {
+			switch {
+			case !t.After(start):
+				continue
+			case r.count > 0 && len(starts) >= r.count,
+				!r.until.IsZero() && t.After(r.until),
+				!t.Before(limit):
+				return starts
+			}
+			starts = append(starts, t)
+		}
+	}
+	return starts
+}
+
+// period returns the candidate starts of the p-th period of the rule in order,
+// and the first day of the period.
+func (r *rule) period(start time.Time, p int) ([]time.Time, time.Time) {
+	y, m, d := start.Date()
+	hour, minute, sec := start.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, sec, 0, start.Location())
+	}
+
+	var candidates []time.Time
+	switch r.freq {
+	case "DAILY":
+		t := at(y, m, d+p*r.interval)
+		if r.matchesMonth(t.Month()) && r.matchesWeekday(t.Weekday()) &&
+			(len(r.byMonthDay) == 0 || slices.Contains(r.monthDays(t.Year(), t.Month(), 0), t.Day())) {
+			candidates = append(candidates, t)
+		}
+		return candidates, at(t.Date())
+	case "WEEKLY":
+		offset := (int(start.Weekday()) - int(r.weekStart) + 7) % 7
+		first := at(y, m, d-offset+7*p*r.interval)
+		for i := range 7 {
+			t := at(first.Year(), first.Month(), first.Day()+i)
+			day := t.Weekday() == start.Weekday()
+			if len(r.byDay) > 0 {
+				day = r.matchesWeekday(t.Weekday())
+			}
+			if day && r.matchesMonth(t.Month()) {
+				candidates = append(candidates, t)
+			}
+		}
+		return candidates, first
+	case "MONTHLY":
+		first := at(y, m+time.Month(p*r.interval), 1)
+		if r.matchesMonth(first.Month()) {
+			for _, day := range r.monthDays(first.Year(), first.Month(), d) {
+				candidates = append(candidates, at(first.Year(), first.Month(), day))
+			}
+		}
+		return candidates, first
+	}
+
+	year := y + p*r.interval
+	months := r.byMonth
+	switch {
+	case len(months) > 0:
+	case len(r.byMonthDay) > 0:
+		months = []time.Month{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	default:
+		months = []time.Month{m}
+	}
+	for _, month := range months {
+		for _, day := range r.monthDays(year, month, d) {
+			candidates = append(candidates, at(year, month, day))
+		}
+	}
+	return candidates, at(year, 1, 1)
+}
+
+// monthDays returns the days of the month selected by BYMONTHDAY and BYDAY in order,
+// or the day of the start when the rule has neither.
+func (r *rule) monthDays(year int, month time.Month, startDay int) []int {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+		if startDay > last {
+			return nil
+		}
+		return []int{startDay}
+	}
+
+	var days []int
+	for day := 1; day <= last; day++ {
+		if len(r.byMonthDay) > 0 && !slices.ContainsFunc(r.byMonthDay, func(n int) bool {
+			return n == day || n == day-last-1
+		}) {
+			continue
+		}
+		if len(r.byDay) > 0 && !slices.ContainsFunc(r.byDay, func(w weekday) bool {
+			return matchesNthWeekday(w, time.Date(year, month, day, 0, 0, 0, 0, time.UTC), last)
+		}) {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// matchesNthWeekday checks if the day of a month with last days is the weekday, e.g. its first Monday.
+func matchesNthWeekday(w weekday, t time.Time, last int) bool {
+	switch {
+	case t.Weekday() != w.day:
+		return false
+	case w.n > 0:
+		return (t.Day()-1)/7+1 == w.n
+	case w.n < 0:
+		return (last-t.Day())/7+1 == -w.n
+	}
+	return true
+}
+
+func (r *rule) matchesMonth(month time.Month) bool {
+	return len(r.byMonth) == 0 || slices.Contains(r.byMonth, month)
+}
+
+func (r *rule) matchesWeekday(day time.Weekday) bool {
+	return len(r.byDay) == 0 || slices.ContainsFunc(r.byDay, func(w weekday) bool { return w.day == day })
+}
+
+// Expand returns the occurrences of the events that overlap the time from from to until,
+// in the order of the events.
+// Recurring events are expanded by their RRULE and RDATE without their EXDATE, and an event with
+// the UID of a recurring event and a RECURRENCE-ID replaces the occurrence starting at that time.
+// Events that could not be read are skipped.
+func Expand(events []Event, from, until time.Time) []Event {
+	recurring := map[string]bool{}
+	overrides := map[string][]*Event{}
+	for i := range events {
+		e := &events[i]
+		switch {
+		case e.Err != nil:
+		case e.Recurring():
+			recurring[e.UID] = true
+		case !e.RecurrenceID.IsZero():
+			overrides[e.UID] = append(overrides[e.UID], e)
+		}
+	}
+
+	overlaps := func(e *Event) bool { return e.End.After(from) && e.Start.Before(until) }
+	var occurrences []Event
+	for i := range events {
+		e := &events[i]
+		switch {
+		case e.Err != nil:
+			// not read, so neither its times nor its recurrence are known
+		case !e.RecurrenceID.IsZero() && recurring[e.UID]:
+			// replaces an occurrence of its recurring event
+		case !e.Recurring():
+			if overlaps(e) {
+				occurrences = append(occurrences, *e)
+			}
+		default:
+			replaced := map[*Event]bool{}
+			for _, start := range e.occurrenceStarts(until) {
+				o := e.occurrence(start)
+				for _, override := range overrides[e.UID] {
+					if override.RecurrenceID.Equal(start) {
+						o = *override
+						replaced[override] = true
+					}
+				}
+				if overlaps(&o) {
+					occurrences = append(occurrences, o)
+				}
+			}
+			// e.g. an occurrence moved from after until into the time
+			for _, override := range overrides[e.UID] {
+				if !replaced[override] && overlaps(override) {
+					occurrences = append(occurrences, *override)
+				}
+			}
+		}
+	}
+	return occurrences
+}
+
+// occurrenceStarts returns the starts of the occurrences of a recurring event before limit,
+// in order and without the excluded ones.
+func (e *Event) occurrenceStarts(limit time.Time) []time.Time {
+	starts := []time.Time{e.Start}
+	if e.rule != nil {
+		starts = e.rule.starts(e.Start, limit)
+	}
+	for _, t := range e.rdates {
+		if t.Before(limit) && !slices.ContainsFunc(starts, t.Equal) {
+			starts = append(starts, t)
+		}
+	}
+	slices.SortFunc(starts, time.Time.Compare)
+	return slices.DeleteFunc(starts, func(t time.Time) bool {
+		return slices.ContainsFunc(e.exdates, t.Equal)
+	})
+}
+
+// occurrence returns the occurrence of a recurring event starting at the time.
+func (e *Event) occurrence(start time.Time) Event {
+	o := *e
+	o.Start = start
+	if e.AllDay {
+		days := int(math.Round(e.End.Sub(e.Start).Hours() / 24))
+		o.End = start.AddDate(0, 0, days)
+	} else {
+		o.End = start.Add(e.End.Sub(e.Start))
+	}
+	o.rule, o.rdates, o.exdates = nil, nil, nil
+	return o
+}
@@ -0,0 +1,171 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpand(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		event    string
+		expected []string
+	}{
+		{
+			name:     "single event",
+			event:    "DTSTART:20260105T090000Z\nDTEND:20260105T100000Z\n",
+			expected: []string{"2026-01-05T09:00"},
+		},
+		{
+			name:  "daily with count",
+			event: "DTSTART:20260105T090000Z\nDTEND:20260105T100000Z\nRRULE:FREQ=DAILY;COUNT=3\n",
+			expected: []string{
+				"2026-01-05T09:00", "2026-01-06T09:00", "2026-01-07T09:00",
+			},
+		},
+		{
+			name:  "weekly on weekdays until a date",
+			event: "DTSTART:20260105T090000Z\nDTEND:20260105T100000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20260114\n",
+			expected: []string{
+				"2026-01-05T09:00", "2026-01-07T09:00", "2026-01-12T09:00", "2026-01-14T09:00",
+			},
+		},
+		{
+			name:  "every other week",
+			event: "DTSTART:20260302T090000Z\nDTEND:20260302T100000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2\n",
+			expected: []string{
+				"2026-03-02T09:00", "2026-03-16T09:00", "2026-03-30T09:00",
+			},
+		},
+		{
+			name:  "monthly on the last friday",
+			event: "DTSTART:20260130T180000Z\nDTEND:20260130T200000Z\nRRULE:FREQ=MONTHLY;BYDAY=-1FR\n",
+			expected: []string{
+				"2026-01-30T18:00", "2026-02-27T18:00", "2026-03-27T18:00",
+			},
+		},
+		{
+			name:  "monthly on a day missing in some months",
+			event: "DTSTART:20260131T090000Z\nDTEND:20260131T100000Z\nRRULE:FREQ=MONTHLY\n",
+			expected: []string{
+				"2026-01-31T09:00", "2026-03-31T09:00",
+			},
+		},
+		{
+			name:     "yearly from before the time",
+			event:    "DTSTART;VALUE=DATE:20201224\nDTEND;VALUE=DATE:20210102\nRRULE:FREQ=YEARLY\n",
+			expected: []string{"2025-12-24T00:00"},
+		},
+		{
+			name:  "yearly in a month",
+			event: "DTSTART:20250315T090000Z\nDTEND:20250315T100000Z\nRRULE:FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=1,-1\n",
+			expected: []string{
+				"2026-03-01T09:00", "2026-03-31T09:00",
+			},
+		},
+		{
+			name: "extra and excluded dates",
+			event: "DTSTART:20260105T090000Z\nDTEND:20260105T100000Z\nRRULE:FREQ=WEEKLY;COUNT=3\n" +
+				"EXDATE:20260112T090000Z\nRDATE:20260110T090000Z,20260301T090000Z\n",
+			expected: []string{
+				"2026-01-05T09:00", "2026-01-10T09:00", "2026-01-19T09:00", "2026-03-01T09:00",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := Parse(strings.NewReader("BEGIN:VEVENT\n"+tt.event+"END:VEVENT\n"), time.UTC)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var starts []string
+			for _, e := range Expand(events, from, until) {
+				starts = append(starts, e.Start.UTC().Format("2006-01-02T15:04"))
+			}
+			if strings.Join(starts, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("expected %q, got %q", tt.expected, starts)
+			}
+		})
+	}
+}
+
+func TestExpand_Overrides(t *testing.T) {
+	content := "BEGIN:VEVENT\n" +
+		"UID:standup\n" +
+		"SUMMARY:Standup\n" +
+		"DTSTART;TZID=Europe/Berlin:20260105T090000\n" +
+		"DURATION:PT15M\n" +
+		"RRULE:FREQ=DAILY;COUNT=3\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:standup\n" +
+		"SUMMARY:Moved standup\n" +
+		"RECURRENCE-ID;TZID=Europe/Berlin:20260106T090000\n" +
+		"DTSTART;TZID=Europe/Berlin:20260106T110000\n" +
+		"DURATION:PT15M\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:standup\n" +
+		"SUMMARY:Standup\n" +
+		"STATUS:CANCELLED\n" +
+		"RECURRENCE-ID;TZID=Europe/Berlin:20260107T090000\n" +
+		"DTSTART;TZID=Europe/Berlin:20260107T090000\n" +
+		"DURATION:PT15M\n" +
+		"END:VEVENT\n"
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+	events, err := Parse(strings.NewReader(content), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	occurrences := Expand(events, time.Date(2026, 1, 1, 0, 0, 0, 0, berlin), time.Date(2026, 2, 1, 0, 0, 0, 0, berlin))
+	if len(occurrences) != 3 {
+		t.Fatalf("expected 3 occurrences, got %+v", occurrences)
+	}
+	if o := occurrences[0]; o.Summary != "Standup" || !o.Start.Equal(time.Date(2026, 1, 5, 9, 0, 0, 0, berlin)) ||
+		o.End.Sub(o.Start) != 15*time.Minute {
+		t.Errorf("unexpected first occurrence %+v", o)
+	}
+	if o := occurrences[1]; o.Summary != "Moved standup" || !o.Start.Equal(time.Date(2026, 1, 6, 11, 0, 0, 0, berlin)) {
+		t.Errorf("unexpected moved occurrence %+v", o)
+	}
+	if o := occurrences[2]; !o.Cancelled() {
+		t.Errorf("expected a cancelled occurrence, got %+v", o)
+	}
+}
+
+func TestParse_InvalidRecurrence(t *testing.T) {
+	tests := map[string]string{
+		"missing frequency":   "RRULE:COUNT=3",
+		"unsupported part":    "RRULE:FREQ=DAILY;BYHOUR=9,17",
+		"unsupported freq":    "RRULE:FREQ=HOURLY",
+		"numbered weekly day": "RRULE:FREQ=WEEKLY;BYDAY=1MO",
+		"invalid interval":    "RRULE:FREQ=DAILY;INTERVAL=0",
+		"multiple rules":      "RRULE:FREQ=DAILY\nRRULE:FREQ=WEEKLY",
+		"invalid exdate":      "RRULE:FREQ=DAILY\nEXDATE:tomorrow",
+		"period rdate":        "RDATE;VALUE=PERIOD:20260105T090000Z/PT1H",
+		"recurrence id range": "RECURRENCE-ID;RANGE=THISANDFUTURE:20260105T090000Z",
+		"yearly weekday":      "RRULE:FREQ=YEARLY;BYDAY=MO",
+	}
+
+	for name, recurrence := range tests {
+		t.Run(name, func(t *testing.T) {
+			content := "BEGIN:VEVENT\nDTSTART:20260105T090000Z\n" + recurrence + "\nEND:VEVENT\n"
+			events, err := Parse(strings.NewReader(content), time.UTC)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(events) != 1 || events[0].Err == nil {
+				t.Errorf("expected an event with an error, got %+v", events)
+			}
+		})
+	}
+}
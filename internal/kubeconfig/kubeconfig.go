@@ -0,0 +1,205 @@
+// Package kubeconfig provides native kubeconfig loading for kubectl-guard.
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVar is the environment variable holding the kubeconfig search list.
+const EnvVar = "KUBECONFIG"
+
+// DefaultNamespace is used when neither args nor the context set a namespace.
+const DefaultNamespace = "default"
+
+// Config represents the merged kubeconfig.
+type Config struct {
+	CurrentContext string
+	Clusters       map[string]Cluster
+	Contexts       map[string]Context
+	Users          map[string]User
+}
+
+// Cluster represents a cluster entry in kubeconfig.
+type Cluster struct {
+	Server string `yaml:"server"`
+}
+
+// Context represents a context entry in kubeconfig.
+type Context struct {
+	Cluster   string `yaml:"cluster"`
+	User      string `yaml:"user"`
+	Namespace string `yaml:"namespace"`
+}
+
+// User represents a user entry in kubeconfig.
+// Credentials are intentionally not loaded.
+type User struct{}
+
+// Overrides represents values passed on the kubectl command line.
+type Overrides struct {
+	Context   string
+	Cluster   string
+	User      string
+	Namespace string
+}
+
+// Target represents the resolved cluster, user and namespace of a context.
+type Target struct {
+	Context   string
+	Cluster   string
+	User      string
+	Namespace string
+	Server    string
+}
+
+type file struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string  `yaml:"name"`
+		Cluster Cluster `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string  `yaml:"name"`
+		Context Context `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+	} `yaml:"users"`
+}
+
+// Paths returns the kubeconfig files to load in precedence order, as kubectl does:
+// an explicit --kubeconfig path wins, then the KUBECONFIG list, then ~/.kube/config.
+func Paths(explicit string) ([]string, error) {
+	if explicit != "" {
+		return []string{explicit}, nil
+	}
+	if env := os.Getenv(EnvVar); env != "" {
+		var paths []string
+		for _, p := range filepath.SplitList(env) {
+			if p != "" {
+				paths = append(paths, p)
+			}
+		}
+		return paths, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return []string{filepath.Join(home, ".kube", "config")}, nil
+}
+
+// Load loads and merges the kubeconfig files selected by Paths.
+// An explicitly given file must exist; missing files from KUBECONFIG or the default path are skipped.
+func Load(explicit string) (*Config, error) {
+	paths, err := Paths(explicit)
+	if err != nil {
+		return nil, err
+	}
+	if explicit != "" {
+		if _, err := os.Stat(explicit); err != nil {
+			return nil, err
+		}
+	}
+	return LoadFiles(paths)
+}
+
+// LoadFiles loads and merges kubeconfig files.
+// The first file to set a value wins, matching kubectl's merge rules.
+func LoadFiles(paths []string) (*Config, error) {
+	cfg := &Config{
+		Clusters: map[string]Cluster{},
+		Contexts: map[string]Context{},
+		Users:    map[string]User{},
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		var f file
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		if cfg.CurrentContext == "" {
+			cfg.CurrentContext = f.CurrentContext
+		}
+		for _, c := range f.Clusters {
+			if _, ok := cfg.Clusters[c.Name]; !ok {
+				cfg.Clusters[c.Name] = c.Cluster
+			}
+		}
+		for _, c := range f.Contexts {
+			if _, ok := cfg.Contexts[c.Name]; !ok {
+				cfg.Contexts[c.Name] = c.Context
+			}
+		}
+		for _, u := range f.Users {
+			if _, ok := cfg.Users[u.Name]; !ok {
+				cfg.Users[u.Name] = User{}
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// ContextNames returns the sorted names of all contexts.
+func (c *Config) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve resolves the effective target, applying command-line overrides over the kubeconfig.
+func (c *Config) Resolve(o Overrides) (*Target, error) {
+	name := o.Context
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" {
+		return nil, errors.New("current-context is not set")
+	}
+
+	ctx, ok := c.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("context %q does not exist", name)
+	}
+
+	target := &Target{
+		Context:   name,
+		Cluster:   ctx.Cluster,
+		User:      ctx.User,
+		Namespace: ctx.Namespace,
+	}
+	if o.Cluster != "" {
+		target.Cluster = o.Cluster
+	}
+	if o.User != "" {
+		target.User = o.User
+	}
+	if o.Namespace != "" {
+		target.Namespace = o.Namespace
+	}
+	if target.Namespace == "" {
+		target.Namespace = DefaultNamespace
+	}
+	if cluster, ok := c.Clusters[target.Cluster]; ok {
+		target.Server = cluster.Server
+	}
+	return target, nil
+}
@@ -0,0 +1,184 @@
+package kubeconfig
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPaths(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+
+	t.Run("explicit path wins", func(t *testing.T) {
+		t.Setenv(EnvVar, "/a:/b")
+		paths, err := Paths("/explicit")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(paths, []string{"/explicit"}) {
+			t.Errorf("unexpected paths: %v", paths)
+		}
+	})
+
+	t.Run("KUBECONFIG list", func(t *testing.T) {
+		t.Setenv(EnvVar, "/a::/b")
+		paths, err := Paths("")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(paths, []string{"/a", "/b"}) {
+			t.Errorf("unexpected paths: %v", paths)
+		}
+	})
+
+	t.Run("default path", func(t *testing.T) {
+		t.Setenv(EnvVar, "")
+		paths, err := Paths("")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(paths, []string{"/home/test/.kube/config"}) {
+			t.Errorf("unexpected paths: %v", paths)
+		}
+	})
+}
+
+func TestLoadFiles_Merge(t *testing.T) {
+	cfg, err := LoadFiles([]string{
+		filepath.Join("testdata", "base.yaml"),
+		filepath.Join("testdata", "missing.yaml"),
+		filepath.Join("testdata", "override.yaml"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The first file to set current-context wins
+	if cfg.CurrentContext != "dev" {
+		t.Errorf("expected current-context 'dev', got %q", cfg.CurrentContext)
+	}
+	// The first file to define a context wins
+	if cfg.Contexts["prod"].Namespace != "payments" {
+		t.Errorf("expected prod namespace 'payments', got %q", cfg.Contexts["prod"].Namespace)
+	}
+	if cfg.Clusters["prod-cluster"].Server != "https://prod.example.com" {
+		t.Errorf("unexpected prod server: %q", cfg.Clusters["prod-cluster"].Server)
+	}
+	// Entries only present in later files are merged in
+	if _, ok := cfg.Contexts["staging"]; !ok {
+		t.Error("expected staging context to be merged")
+	}
+
+	names := cfg.ContextNames()
+	if !reflect.DeepEqual(names, []string{"dev", "prod", "staging"}) {
+		t.Errorf("unexpected context names: %v", names)
+	}
+}
+
+func TestLoadFiles_Invalid(t *testing.T) {
+	_, err := LoadFiles([]string{filepath.Join("testdata", "invalid.yaml")})
+	if err == nil {
+		t.Error("expected error for invalid kubeconfig")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv(EnvVar, filepath.Join("testdata", "override.yaml")+string(filepath.ListSeparator)+filepath.Join("testdata", "base.yaml"))
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.CurrentContext != "prod" {
+		t.Errorf("expected current-context 'prod', got %q", cfg.CurrentContext)
+	}
+
+	cfg, err = Load(filepath.Join("testdata", "base.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.CurrentContext != "dev" {
+		t.Errorf("expected --kubeconfig to ignore KUBECONFIG, got %q", cfg.CurrentContext)
+	}
+
+	if _, err := Load(filepath.Join("testdata", "missing.yaml")); err == nil {
+		t.Error("expected error for missing explicit kubeconfig")
+	}
+}
+
+func TestConfig_Resolve(t *testing.T) {
+	cfg, err := LoadFiles([]string{filepath.Join("testdata", "base.yaml")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		overrides Overrides
+		expected  *Target
+		wantErr   bool
+	}{
+		{
+			name: "current context",
+			expected: &Target{
+				Context:   "dev",
+				Cluster:   "dev-cluster",
+				User:      "dev-user",
+				Namespace: "default",
+				Server:    "https://dev.example.com:6443",
+			},
+		},
+		{
+			name:      "context override",
+			overrides: Overrides{Context: "prod"},
+			expected: &Target{
+				Context:   "prod",
+				Cluster:   "prod-cluster",
+				User:      "prod-user",
+				Namespace: "payments",
+				Server:    "https://prod.example.com",
+			},
+		},
+		{
+			name:      "all overrides",
+			overrides: Overrides{Context: "dev", Cluster: "prod-cluster", User: "prod-user", Namespace: "kube-system"},
+			expected: &Target{
+				Context:   "dev",
+				Cluster:   "prod-cluster",
+				User:      "prod-user",
+				Namespace: "kube-system",
+				Server:    "https://prod.example.com",
+			},
+		},
+		{
+			name:      "unknown context",
+			overrides: Overrides{Context: "missing"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := cfg.Resolve(tt.overrides)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConfig_Resolve_NoCurrentContext(t *testing.T) {
+	cfg := &Config{}
+	if _, err := cfg.Resolve(Overrides{}); err == nil {
+		t.Error("expected error when current-context is not set")
+	}
+}